@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"postgres_performance_test/pkg/bench"
+	"strings"
+	"time"
+)
+
+// cleanup is the cleanup subcommand, it removes the schemas, databases and
+// keys of the runs that were kept or not removed, e.g. after a crash:
+//
+//	postgres_performance_test cleanup --older-than 24h
+func cleanup(args []string) {
+	flags := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	databases := flags.String("db", "postgres,mongodb", "databases to clean up, comma separated: postgres, mongodb, mysql, redis")
+	postgresDSN := flags.String("postgres-dsn", bench.DefaultPostgresDSN, "PostgreSQL data source name")
+	mongoURI := flags.String("mongodb-uri", bench.DefaultMongoDBURI, "MongoDB connection URI")
+	mysqlServer := flags.String("mysql-dsn", bench.DefaultMySQLDSN, "MySQL data source name")
+	redisServer := flags.String("redis-addr", bench.DefaultRedisAddr, "Redis server address")
+	olderThan := flags.Duration("older-than", bench.DefaultCleanupAge, "remove only the runs started longer ago, so running benchmarks keep their data")
+	dryRun := flags.Bool("dry-run", false, "list the leftovers without removing them")
+	if err := flags.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	cutoff, err := bench.CleanupCutoff(*olderThan)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	failed := false
+	for _, db := range strings.Split(*databases, ",") {
+		var removed []string
+		var err error
+		switch db = strings.ToLower(strings.TrimSpace(db)); db {
+		case "postgres":
+			removed, err = bench.CleanupPostgres(ctx, *postgresDSN, cutoff, *dryRun)
+		case "mongodb":
+			removed, err = bench.CleanupMongoDB(ctx, *mongoURI, cutoff, *dryRun)
+		case "mysql":
+			removed, err = bench.CleanupMySQL(ctx, *mysqlServer, cutoff, *dryRun)
+		case "redis":
+			removed, err = bench.CleanupRedis(ctx, *redisServer, cutoff, *dryRun)
+		default:
+			log.Fatalf("unknown database %q, expected postgres, mongodb, mysql or redis", db)
+		}
+
+		verb := "removed"
+		if *dryRun {
+			verb = "found"
+			for _, name := range removed {
+				log.Printf("%s: leftover %s", db, name)
+			}
+		}
+		if err != nil {
+			log.Printf("%s: cleanup failed: %v", db, err)
+			failed = true
+			continue
+		}
+		log.Printf("%s: %s %d leftover runs", db, verb, len(removed))
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
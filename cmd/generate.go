@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"postgres_performance_test/pkg/bench"
+	"time"
+)
+
+// generate is the generate subcommand, it writes the dataset of the profile
+// to files instead of loading it into a database:
+//
+//	postgres_performance_test generate --profile small --format copy --out ./data
+func generate(args []string) {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	format := flags.String("format", "csv", "format of the files: csv, jsonl or copy, the text format of the Postgres COPY")
+	out := flags.String("out", ".", "directory of the users, articles and comments files")
+	profileName := flags.String("profile", "", "dataset profile: tiny, small, medium, large, their skewed variants like large-skewed or custom, the profile of the config file if not set")
+	rows := flags.Int("rows", 10000, "rows of every table of the custom profile")
+	seed := flags.Int64("seed", 0, "seed of the generated data, the same seed generates the rows the benchmark loads")
+	configPath := flags.String("config", "", "benchmark config file (JSON) with the profiles and the generators of the fields")
+	if err := flags.Parse(args); err != nil {
+		log.Fatal(err)
+	}
+
+	var config bench.Config
+	if *configPath != "" {
+		var err error
+		if config, err = bench.LoadConfig(*configPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	name := *profileName
+	if name == "" && config.Profile == "" {
+		name = bench.CustomProfileName
+	}
+	profile, err := config.Dataset(name, *rows)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := config.Generator(*seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start := time.Now()
+	log.Printf("Generate dataset %s with seed %d", profile, *seed)
+	if err := bench.Generate(*out, *format, profile, data); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Overall time %s", time.Since(start))
+}
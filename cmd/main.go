@@ -1,36 +1,140 @@
 package main
 
 import (
-	_ "github.com/lib/pq"
+	"context"
+	"flag"
 	"log"
-	"postgres_performance_test/internal/mongodb"
-	"postgres_performance_test/internal/postgres"
-	_ "postgres_performance_test/migration"
+	"os"
+	"os/signal"
+	"postgres_performance_test/pkg/bench"
 	"postgres_performance_test/pkg/keyboard"
+	"syscall"
 	"time"
 )
 
+var onError = flag.String("on-error", "abort", "what to do when an operation fails: abort, continue or retry")
+var maxErrors = flag.Int64("max-errors", 0, "abort the run after this many failed operations, 0 - unlimited")
+var retries = flag.Int("retries", 3, "how many times a failed operation is repeated, transient errors are always retried")
+var retryDelay = flag.Duration("retry-delay", 50*time.Millisecond, "backoff before the first retry, doubled with every retry")
+var retryMaxDelay = flag.Duration("retry-max-delay", 2*time.Second, "maximum backoff between retries")
+var pgFlavor = flag.String("pg-flavor", "postgres", "database behind the postgres backend: postgres, cockroach or yugabyte")
+var pgGooseCommand = flag.String("pg-goose-command", "status", "goose command the postgres backend runs before the migrations, empty - none")
+var pgMigrationsDir = flag.String("pg-migrations-dir", "", "directory of the postgres migrations on disk, the migrations in the binary if not set")
+var sqlitePath = flag.String("sqlite-path", bench.DefaultSQLitePath, "SQLite database file, :memory: keeps the database in memory")
+var sqliteJournal = flag.String("sqlite-journal", "wal", "SQLite journal mode: wal or rollback")
+var mysqlDSN = flag.String("mysql-dsn", bench.DefaultMySQLDSN, "MySQL data source name, multiStatements=true is required by the migrations")
+var redisAddr = flag.String("redis-addr", bench.DefaultRedisAddr, "Redis server address, miniredis starts an in-process stand-in")
+var configPath = flag.String("config", "", "benchmark config file (JSON) with the generators of the inserted fields")
+var seed = flag.Int64("seed", 0, "seed of the generated data, the selected keys and the operations, runs with the same seed repeat the same operations per worker")
+var resultPath = flag.String("result", "", "write the results as JSON to this file")
+var payloadSizes = flag.String("payload-sizes", "", "text sizes of the payload sweep, comma separated, e.g. 100,2KB,8KB,64KB,1MB, the sweep only runs if it is set. It inserts and reads articles in a payload_sweep table or collection created for every size, so the storage is that of the payloads and not of the loaded articles")
+var profileName = flag.String("profile", "", "dataset profile: tiny, small, medium, large, their skewed variants like large-skewed or custom, asked for if neither this flag nor the config file sets it")
+var importDir = flag.String("import", "", "import the tables from users, articles and comments CSV or JSONL files in this directory instead of generating them")
+var schemaNames = flag.String("schemas", bench.DefaultSchema, "postgres schema variants loaded and benchmarked with the same workload, comma separated, e.g. default,no-fk,uuid,serial,no-id-index,varchar(255),nullable, options are joined by +, varchar(n) cuts the generated texts to n characters, sqlite runs only varchar(n)")
+var cleanupPolicy = flag.String("cleanup", "always", "when a run removes its postgres schema, mongodb or mysql database or redis keys: always, on-success or never, the cleanup subcommand removes the kept ones")
+var rate = flag.Float64("rate", 0, "operations per second of the workers of a scenario together, 0 - as fast as the database allows")
+var verify = flag.Bool("verify", false, "verify referential integrity and checksums of the loaded data, this scans every table")
+
 func main() {
 	var err error
 	amount := 10000
 
-	dbType, err := keyboard.GetIntegerInput("Enter DB type: 1 - postgres, 2 - mongodb ")
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		generate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "cleanup" {
+		cleanup(os.Args[2:])
+		return
+	}
+
+	flag.Parse()
+	errorPolicy := bench.ErrorPolicy{
+		MaxErrors: *maxErrors,
+		Retry:     bench.RetryPolicy{Retries: *retries, Delay: *retryDelay, MaxDelay: *retryMaxDelay},
+	}
+	errorPolicy.OnError, err = bench.ParseOnError(*onError)
 	if err != nil {
-		panic(err)
+		log.Fatal(err)
+	}
+	var sizes []int
+	if *payloadSizes != "" {
+		sizes, err = bench.ParseSizes(*payloadSizes)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	cleanupAfter, err := bench.ParseCleanup(*cleanupPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	schemas, err := bench.ParseSchemas(*schemaNames)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(schemas) == 0 {
+		schemas = []bench.Schema{{}}
 	}
 
-	useTestSchema, err := keyboard.GetIntegerInput("Use test schema [100k users, 1 m articles, 10 m comments], 0 - no, 1 - yes , default - 0 :")
+	dbType, err := keyboard.GetIntegerInput("Enter DB type: 1 - postgres, 2 - mongodb, 3 - sqlite, 4 - mysql, 5 - redis ")
 	if err != nil {
-		useTestSchema = 0
+		panic(err)
+	}
+
+	var config bench.Config
+	if *configPath != "" {
+		config, err = bench.LoadConfig(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fixtures := config.Import
+	if *importDir != "" {
+		found, err := bench.FindFixtures(*importDir)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for table, source := range config.Import {
+			found[table] = source
+		}
+		fixtures = found
+	}
+	if err := fixtures.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	// the imported files define the dataset
+	name := *profileName
+	if name == "" {
+		name = config.Profile
+	}
+	if name == "" && len(fixtures) > 0 {
+		name = bench.CustomProfileName
+	}
+	if name == "" {
+		choice, err := keyboard.GetIntegerInput("Dataset profile: 0 - custom, 1 - tiny, 2 - small, 3 - medium, 4 - large [100k users, 1 m articles, 10 m comments], 5-8 - the same skewed, default - 0 :")
+		if err != nil || choice < 1 || choice >= len(bench.ProfileNames) {
+			choice = 0
+		}
+		name = bench.CustomProfileName
+		if choice > 0 {
+			name = bench.ProfileNames[choice-1]
+		}
 	}
 
-	if useTestSchema == 0 {
+	if _, defined := config.Profiles[name]; name == bench.CustomProfileName && !defined && len(fixtures) == 0 {
 		amount, err = keyboard.GetIntegerInput("Enter table rows count ")
 		if err != nil {
 			panic(err)
 		}
 	}
 
+	profile, err := config.Dataset(name, amount)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	poolCount, err := keyboard.GetIntegerInput("Enter connection pool size ")
 	if err != nil {
 		panic(err)
@@ -45,20 +149,89 @@ func main() {
 		passTestCount = 0
 	}
 
+	// the first Ctrl-C stops the workers and runs the cleanup, the second one kills the process
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	start := time.Now()
 	log.Print("========== START ============")
 
+	options := bench.Options{
+		Rows:         amount,
+		Workers:      poolCount,
+		Skip:         passTestCount,
+		Profile:      profile,
+		Fixtures:     fixtures,
+		Verify:       *verify,
+		ErrorPolicy:  errorPolicy,
+		PayloadSizes: sizes,
+		Cleanup:      cleanupAfter,
+		Rate:         *rate,
+	}
+	options.Data, err = config.Generator(*seed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var backend bench.Backend
 	if dbType == 1 {
 		runMigrations, err := keyboard.GetIntegerInput("Run migrations 0 - yes, 1 - no, default - 0 ")
 		if err != nil {
 			runMigrations = 0
 		}
-		postgres.StartTest(amount, poolCount, passTestCount, runMigrations, useTestSchema)
+		flavor, err := bench.ParseFlavor(*pgFlavor)
+		if err != nil {
+			log.Fatal(err)
+		}
+		backend = bench.Postgres(bench.DefaultPostgresDSN, runMigrations == 0).WithFlavor(flavor).WithMigrations(*pgGooseCommand, *pgMigrationsDir)
 	} else if dbType == 2 {
-		mongodb.StartTest(amount, poolCount, passTestCount, useTestSchema)
+		backend = bench.MongoDB(bench.DefaultMongoDBURI)
+	} else if dbType == 3 {
+		journal, err := bench.ParseJournal(*sqliteJournal)
+		if err != nil {
+			log.Fatal(err)
+		}
+		backend = bench.SQLite(*sqlitePath, journal)
+	} else if dbType == 4 {
+		backend = bench.MySQL(*mysqlDSN)
+	} else if dbType == 5 {
+		backend = bench.Redis(*redisAddr)
 	} else {
 		panic("Invalid DB type selected")
 	}
+	// sqlite runs the varchar(n) variants only, its setup rejects the others
+	if dbType != 1 && dbType != 3 && (len(schemas) > 1 || !schemas[0].IsDefault()) {
+		log.Fatal("Schema variants are only supported by postgres and sqlite")
+	}
+
+	// every variant is loaded and benchmarked from scratch
+	var comparison bench.Comparison
+	for _, schema := range schemas {
+		if ctx.Err() != nil {
+			break
+		}
+		options.Schema = schema
+		report := bench.NewRunner(backend, options).Run(ctx)
+		report.Log()
+		comparison = append(comparison, report)
+	}
+
+	if len(comparison) > 1 {
+		comparison.Log()
+	}
+	if *resultPath != "" && len(comparison) > 0 {
+		save := comparison[0].Save
+		if len(comparison) > 1 {
+			save = comparison.Save
+		}
+		if err := save(*resultPath); err != nil {
+			log.Printf("Can not write the results to %s: %v", *resultPath, err)
+		}
+	}
 
 	t := time.Now()
 	elapsed := t.Sub(start)
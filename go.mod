@@ -3,13 +3,19 @@ module postgres_performance_test
 go 1.19
 
 require (
+	github.com/alicebob/miniredis/v2 v2.30.0
+	github.com/go-sql-driver/mysql v1.7.1
 	github.com/lib/pq v1.10.6
+	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/pressly/goose/v3 v3.7.0
-	github.com/valyala/fastrand v1.1.0
+	github.com/redis/go-redis/v9 v9.0.5
 	go.mongodb.org/mongo-driver v1.11.1
 )
 
 require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/golang/snappy v0.0.1 // indirect
 	github.com/klauspost/compress v1.13.6 // indirect
 	github.com/montanaflynn/stats v0.0.0-20171201202039-1bf9dbcd8cbe // indirect
@@ -18,6 +24,7 @@ require (
 	github.com/xdg-go/scram v1.1.1 // indirect
 	github.com/xdg-go/stringprep v1.0.3 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20181117223130-1be2e3e5546d // indirect
+	github.com/yuin/gopher-lua v0.0.0-20220504180219-658193537a64 // indirect
 	golang.org/x/crypto v0.0.0-20220722155217-630584e8d5aa // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/sys v0.4.0 // indirect
@@ -0,0 +1,112 @@
+// Package benchtest runs the scenarios as Go benchmarks. It is apart from
+// the bench package, so the binaries importing that do not link testing.
+package benchtest
+
+import (
+	"context"
+	"postgres_performance_test/internal/bench"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// BenchmarkScenario runs the scenario called name as a Go benchmark. The
+// backend is set up and the scenarios before name are run untimed to load the
+// data it needs, then the Op of the scenario is run b.N times by BenchmarkOp.
+// Scenarios without an Op are skipped.
+func BenchmarkScenario(b *testing.B, backend bench.Backend, options bench.Options, name string) {
+	b.Helper()
+	b.StopTimer()
+	if options.RunID == "" {
+		options.RunID = bench.NewRunID()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := bench.NewErrors(options.ErrorPolicy, backend.Classify, backend.Retryable, cancel)
+
+	defer backend.Teardown()
+	if err := backend.Setup(ctx, options, errs); err != nil {
+		b.Fatalf("setup of %s failed: %v", backend.Name(), err)
+	}
+
+	var op func(ctx context.Context, errs *bench.Errors, position int) error
+	found := false
+	for _, scenario := range backend.Scenarios() {
+		if scenario.Name == name {
+			op, found = scenario.Op, true
+			if scenario.Unsupported != "" {
+				b.Skipf("%s does not support %q: %s", backend.Name(), name, scenario.Unsupported)
+			}
+			break
+		}
+		if scenario.Unsupported == "" {
+			scenario.Run(ctx, errs)
+		}
+	}
+	if !found {
+		b.Fatalf("%s has no scenario %q", backend.Name(), name)
+	}
+	if op == nil {
+		b.Skipf("scenario %q can not be repeated", name)
+	}
+	if err := errs.Err(); err != nil {
+		b.Fatalf("loading the data for %q failed: %v", name, err)
+	}
+	errs.Take()
+
+	BenchmarkOp(b, options.Workers, func(position int) error {
+		return op(ctx, errs, position)
+	})
+	if err := errs.Err(); err != nil {
+		b.Fatalf("%q failed: %v", name, err)
+	}
+}
+
+// BenchmarkOp runs op b.N times through b.RunParallel with at least workers
+// goroutines, every call gets a distinct position starting at 0. Besides
+// ns/op it reports rows/s, the p50, p95 and p99 latencies and the failed
+// operations, if any.
+func BenchmarkOp(b *testing.B, workers int, op func(position int) error) {
+	b.Helper()
+
+	if procs := runtime.GOMAXPROCS(0); workers > procs {
+		b.SetParallelism((workers + procs - 1) / procs)
+	}
+
+	var mx sync.Mutex
+	var collectors []*bench.Collector
+	var next int64
+
+	b.ResetTimer()
+	b.StartTimer()
+	start := time.Now()
+	b.RunParallel(func(pb *testing.PB) {
+		collector := &bench.Collector{}
+		for pb.Next() {
+			position := int(atomic.AddInt64(&next, 1) - 1)
+			opStart := time.Now()
+			err := op(position)
+			collector.Observe(time.Since(opStart), err)
+		}
+
+		mx.Lock()
+		defer mx.Unlock()
+		collectors = append(collectors, collector)
+	})
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	metrics := bench.Merge(collectors)
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		b.ReportMetric(float64(metrics.Done)/seconds, "rows/s")
+	}
+	b.ReportMetric(float64(metrics.P50.Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(metrics.P95.Nanoseconds()), "p95-ns")
+	b.ReportMetric(float64(metrics.P99.Nanoseconds()), "p99-ns")
+	if metrics.Failed > 0 {
+		b.ReportMetric(float64(metrics.Failed), "errors")
+	}
+}
@@ -0,0 +1,68 @@
+package benchtest
+
+import (
+	"context"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/fake"
+	"testing"
+)
+
+// recordingBackend is the fake backend remembering the options of its setup
+type recordingBackend struct {
+	*fake.Backend
+	setups  int
+	options bench.Options
+}
+
+func (r *recordingBackend) Setup(ctx context.Context, options bench.Options, errs *bench.Errors) error {
+	r.setups++
+	r.options = options
+	return r.Backend.Setup(ctx, options, errs)
+}
+
+func TestBenchmarkScenarioRunsOpBNTimes(t *testing.T) {
+	backend := &recordingBackend{Backend: fake.New(fake.Config{Seed: 1})}
+
+	result := testing.Benchmark(func(b *testing.B) {
+		BenchmarkScenario(b, backend, bench.Options{Rows: 10, Workers: 4}, "insert articles")
+		// the users are loaded untimed, the articles are the b.N operations
+		if users, articles := backend.Rows("users"), backend.Rows("articles"); users != 10 || articles != b.N {
+			b.Errorf("%d users and %d articles inserted, b.N is %d", users, articles, b.N)
+		}
+	})
+
+	if result.N == 0 || backend.setups == 0 {
+		t.Fatalf("unexpected result %+v after %d setups", result, backend.setups)
+	}
+	for _, metric := range []string{"rows/s", "p50-ns", "p95-ns", "p99-ns"} {
+		if _, ok := result.Extra[metric]; !ok {
+			t.Errorf("metric %s is not reported", metric)
+		}
+	}
+	if _, ok := result.Extra["errors"]; ok {
+		t.Errorf("unexpected errors metric %v", result.Extra["errors"])
+	}
+}
+
+func TestBenchmarkScenarioSkipsScenariosWithoutOp(t *testing.T) {
+	backend := fake.New(fake.Config{Seed: 1})
+
+	result := testing.Benchmark(func(b *testing.B) {
+		BenchmarkScenario(b, backend, bench.Options{Rows: 10}, "add nullable column")
+		t.Error("BenchmarkScenario did not skip")
+	})
+	if result.N != 0 && len(result.Extra) != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestBenchmarkScenarioSetsRunID(t *testing.T) {
+	backend := &recordingBackend{Backend: fake.New(fake.Config{Seed: 1})}
+
+	testing.Benchmark(func(b *testing.B) {
+		BenchmarkScenario(b, backend, bench.Options{Rows: 10, Workers: 1}, "insert users")
+	})
+	if _, ok := bench.RunStarted(bench.RunPrefix + backend.options.RunID); !ok {
+		t.Fatalf("the benchmark run got the id %q", backend.options.RunID)
+	}
+}
@@ -0,0 +1,99 @@
+package bench
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cleanup says when a run removes its data.
+type Cleanup string
+
+const (
+	// CleanupAlways removes the data after every run, it is the default
+	CleanupAlways Cleanup = "always"
+	// CleanupOnSuccess keeps the data of failed or interrupted runs for inspection
+	CleanupOnSuccess Cleanup = "on-success"
+	// CleanupNever keeps the data of every run
+	CleanupNever Cleanup = "never"
+)
+
+func ParseCleanup(value string) (Cleanup, error) {
+	switch cleanup := Cleanup(strings.ToLower(strings.TrimSpace(value))); cleanup {
+	case "", CleanupAlways:
+		return CleanupAlways, nil
+	case CleanupOnSuccess, CleanupNever:
+		return cleanup, nil
+	}
+	return "", fmt.Errorf("unknown cleanup %q, expected always, on-success or never", value)
+}
+
+// keeps reports if the data of a run that failed or not is kept
+func (c Cleanup) keeps(failed bool) bool {
+	return c == CleanupNever || c == CleanupOnSuccess && failed
+}
+
+// Keeper is a backend that keeps the data of every run apart, e.g. in a
+// schema of its own, so it can leave it behind for inspection.
+type Keeper interface {
+	// Keep makes the following Teardown disconnect without removing the data
+	// of the run
+	Keep()
+}
+
+// RunPrefix starts the names of the schemas and databases of the runs, the
+// cleanup removes the leftovers by it
+const RunPrefix = "bench_"
+
+// runIDTime is the layout of the start time in the run ids, the names stay
+// valid unquoted identifiers
+const runIDTime = "20060102t150405"
+
+// NewRunID is the id of a run started now, the UTC start time and a random
+// suffix, e.g. 20261019t102245_3f9a.
+func NewRunID() string {
+	// the suffix tells apart the runs started in the same second
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return time.Now().UTC().Format(runIDTime) + "_" + hex.EncodeToString(suffix)
+}
+
+// RunStarted is the start time of the run of a schema or database name made
+// of RunPrefix and the run id, ok is false for the other names.
+func RunStarted(name string) (started time.Time, ok bool) {
+	if !strings.HasPrefix(name, RunPrefix) {
+		return time.Time{}, false
+	}
+	id := strings.TrimPrefix(name, RunPrefix)
+	if len(id) < len(runIDTime) {
+		return time.Time{}, false
+	}
+	started, err := time.Parse(runIDTime, id[:len(runIDTime)])
+	return started, err == nil
+}
+
+// DefaultCleanupAge is how long ago the runs the cleanup removes by default
+// started, the runs of the last day may still be running
+const DefaultCleanupAge = 24 * time.Hour
+
+// CleanupCutoff is the start time before which the cleanup removes the runs,
+// olderThan must be positive so the running benchmarks keep their data.
+func CleanupCutoff(olderThan time.Duration) (time.Time, error) {
+	if olderThan <= 0 {
+		return time.Time{}, fmt.Errorf("the age of the runs to clean up must be positive, %s would remove running benchmarks", olderThan)
+	}
+	return time.Now().Add(-olderThan), nil
+}
+
+// Leftovers are the names of the runs started before cutoff.
+func Leftovers(names []string, cutoff time.Time) []string {
+	var leftovers []string
+	for _, name := range names {
+		if started, ok := RunStarted(name); ok && started.Before(cutoff) {
+			leftovers = append(leftovers, name)
+		}
+	}
+	return leftovers
+}
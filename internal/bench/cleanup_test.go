@@ -0,0 +1,97 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// keepingBackend is a scripted backend keeping the runs apart
+type keepingBackend struct {
+	scriptedBackend
+	runID string
+	kept  bool
+}
+
+func (k *keepingBackend) Setup(ctx context.Context, options Options, errs *Errors) error {
+	k.runID = options.RunID
+	return k.setupErr
+}
+
+func (k *keepingBackend) Keep() { k.kept = true }
+
+func TestRunnerKeepsTheDataByCleanup(t *testing.T) {
+	for _, test := range []struct {
+		cleanup Cleanup
+		err     error
+		kept    bool
+	}{
+		{"", errFake, false},
+		{CleanupAlways, errFake, false},
+		{CleanupOnSuccess, nil, false},
+		{CleanupOnSuccess, errFake, true},
+		{CleanupNever, nil, true},
+	} {
+		backend := &keepingBackend{}
+		backend.scenarios = []Scenario{backend.scenario("first", test.err)}
+		report := NewRunner(backend, Options{Cleanup: test.cleanup, ErrorPolicy: ErrorPolicy{OnError: Abort}}).Run(context.Background())
+
+		if backend.kept != test.kept || !backend.tornDown {
+			t.Fatalf("cleanup %q after error %v: kept %v, torn down %v", test.cleanup, test.err, backend.kept, backend.tornDown)
+		}
+		if backend.runID == "" || report.RunID != backend.runID {
+			t.Fatalf("the report has run %q, the backend %q", report.RunID, backend.runID)
+		}
+	}
+
+	backend := &keepingBackend{}
+	NewRunner(backend, Options{RunID: "given"}).Run(context.Background())
+	if backend.runID != "given" {
+		t.Fatalf("the given run id was replaced by %q", backend.runID)
+	}
+}
+
+func TestParseCleanup(t *testing.T) {
+	for value, want := range map[string]Cleanup{"": CleanupAlways, "Always": CleanupAlways, "on-success": CleanupOnSuccess, " never ": CleanupNever} {
+		if cleanup, err := ParseCleanup(value); err != nil || cleanup != want {
+			t.Fatalf("ParseCleanup(%q) = %q, %v, want %q", value, cleanup, err, want)
+		}
+	}
+	if _, err := ParseCleanup("sometimes"); err == nil {
+		t.Fatal("expected an error for an unknown cleanup")
+	}
+}
+
+func TestLeftovers(t *testing.T) {
+	id := NewRunID()
+	started, ok := RunStarted(RunPrefix + id)
+	if !ok || time.Since(started) > time.Minute || time.Since(started) < -time.Second {
+		t.Fatalf("unexpected start %s of run %s", started, id)
+	}
+
+	names := []string{"public", "bench_20230101t120000_00ff", RunPrefix + id, "bench_broken", "test"}
+	if got := Leftovers(names, time.Now().Add(-time.Hour)); len(got) != 1 || got[0] != "bench_20230101t120000_00ff" {
+		t.Fatalf("unexpected leftovers %v", got)
+	}
+	if got := Leftovers(names, time.Now().Add(time.Second)); len(got) != 2 {
+		t.Fatalf("unexpected leftovers %v", got)
+	}
+}
+
+func TestCleanupKeepsFreshRuns(t *testing.T) {
+	cutoff, err := CleanupCutoff(DefaultCleanupAge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh := RunPrefix + NewRunID()
+	old := RunPrefix + time.Now().Add(-DefaultCleanupAge-time.Hour).UTC().Format(runIDTime) + "_00ff"
+	if got := Leftovers([]string{fresh, old}, cutoff); len(got) != 1 || got[0] != old {
+		t.Fatalf("unexpected leftovers %v", got)
+	}
+
+	for _, olderThan := range []time.Duration{0, -time.Hour} {
+		if _, err := CleanupCutoff(olderThan); err == nil {
+			t.Fatalf("expected an error for %s", olderThan)
+		}
+	}
+}
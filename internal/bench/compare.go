@@ -0,0 +1,118 @@
+package bench
+
+import (
+	"fmt"
+	"log"
+	"postgres_performance_test/migration"
+	"strings"
+	"time"
+)
+
+// Comparison is the reports of the same workload against several schema
+// variants, the first one is the baseline of the others.
+type Comparison []*Report
+
+// label is the name of the variant of the report
+func label(report *Report) string {
+	if report.Schema == "" {
+		return migration.DefaultSchema
+	}
+	return report.Schema
+}
+
+// Log prints the elapsed time and the storage of every scenario per variant,
+// with the difference to the baseline.
+func (c Comparison) Log() {
+	if len(c) == 0 {
+		return
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	results := make([]map[string]Result, len(c))
+	width := 20
+	for i, report := range c {
+		report.mx.Lock()
+		results[i] = map[string]Result{}
+		for _, result := range report.Results {
+			if !seen[result.Name] {
+				seen[result.Name] = true
+				names = append(names, result.Name)
+			}
+			results[i][result.Name] = result
+		}
+		report.mx.Unlock()
+		if len(label(report))+2 > width {
+			width = len(label(report)) + 2
+		}
+	}
+
+	log.Printf("========== %s SCHEMA VARIANTS ==========", c[0].Backend)
+	header := fmt.Sprintf("%-35s", "scenario")
+	for _, report := range c {
+		header += fmt.Sprintf(" %-*s", width, label(report))
+	}
+	log.Print(strings.TrimRight(header, " "))
+
+	for _, name := range names {
+		base, baseOk := results[0][name]
+		line := fmt.Sprintf("%-35s", name)
+		storage := fmt.Sprintf("%-35s", "")
+		hasStorage := false
+		for i := range c {
+			result, ok := results[i][name]
+			if !ok || result.Skipped {
+				line += fmt.Sprintf(" %-*s", width, "-")
+				storage += fmt.Sprintf(" %-*s", width, "")
+				continue
+			}
+
+			cell := result.Elapsed.Round(time.Microsecond).String()
+			if i > 0 && baseOk && !base.Skipped {
+				cell += change(int64(result.Elapsed), int64(base.Elapsed))
+			}
+			if result.Partial {
+				cell += " partial"
+			}
+			line += fmt.Sprintf(" %-*s", width, cell)
+
+			cell = ""
+			if result.Storage != nil {
+				hasStorage = true
+				cell = FormatBytes(result.Storage.Total)
+				if i > 0 && baseOk && base.Storage != nil {
+					cell += change(result.Storage.Total, base.Storage.Total)
+				}
+			}
+			storage += fmt.Sprintf(" %-*s", width, cell)
+		}
+		log.Print(strings.TrimRight(line, " "))
+		if hasStorage {
+			log.Print(strings.TrimRight(storage, " "))
+		}
+	}
+
+	for _, report := range c {
+		if report.Err != nil {
+			log.Printf("%s aborted: %v", label(report), report.Err)
+		}
+	}
+	log.Print("==============================")
+}
+
+// change is the difference of value to base in percent
+func change(value, base int64) string {
+	if base == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.0f%%)", float64(value-base)*100/float64(base))
+}
+
+// Save writes the reports as a JSON array to the result file at path.
+func (c Comparison) Save(path string) error {
+	files := make([]reportFile, len(c))
+	for i, report := range c {
+		files[i] = report.file()
+	}
+	return writeJSON(path, files)
+}
@@ -0,0 +1,58 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"postgres_performance_test/migration"
+	"testing"
+)
+
+func TestComparisonOfSchemaVariants(t *testing.T) {
+	var comparison Comparison
+	for _, name := range []string{"default", "no-fk+uuid"} {
+		schema, err := migration.ParseSchema(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		backend := &scriptedBackend{}
+		backend.scenarios = []Scenario{backend.scenario("first", nil)}
+		comparison = append(comparison, NewRunner(backend, Options{Schema: schema}).Run(context.Background()))
+	}
+	if comparison[0].Schema != "" || comparison[1].Schema != "no-fk+uuid" {
+		t.Fatalf("unexpected schemas %q and %q", comparison[0].Schema, comparison[1].Schema)
+	}
+	comparison.Log()
+
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := comparison.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved []struct {
+		Schema  string
+		Results []Result
+	}
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[1].Schema != "no-fk+uuid" || len(saved[1].Results) != 1 {
+		t.Fatalf("unexpected result file %s", content)
+	}
+}
+
+func TestChange(t *testing.T) {
+	if got := change(150, 100); got != " (+50%)" {
+		t.Fatalf("unexpected change %q", got)
+	}
+	if got := change(75, 100); got != " (-25%)" {
+		t.Fatalf("unexpected change %q", got)
+	}
+	if got := change(1, 0); got != "" {
+		t.Fatalf("unexpected change of a zero base %q", got)
+	}
+}
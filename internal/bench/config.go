@@ -0,0 +1,66 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/internal/fixture"
+)
+
+// Config is the benchmark config file, a JSON document like
+//
+//	{
+//		"profile": "custom",
+//		"profiles": {"custom": {
+//			"users": 5000, "articles_per_user": 3, "comments_per_article": 20,
+//			"authors": {"kind": "zipf", "exponent": 1.2},
+//			"commented": {"kind": "viral", "share": 0.01, "weight": 0.5},
+//			"orphan_free": true
+//		}},
+//		"import": {"users": {"path": "dump/users.csv", "columns": {"name": "full_name"}}},
+//		"fields": {"articles.text": {"type": "text", "sentences": {"min": 1, "max": 50}}}
+//	}
+type Config struct {
+	// Profile is the name of the profile of the run
+	Profile string `json:"profile"`
+	// Profiles define the custom profile or override the built-in ones
+	Profiles map[string]Profile `json:"profiles"`
+	// Import are the files the tables are imported from, see fixture.Source
+	Import fixture.Sources `json:"import"`
+	// Fields configures the data generator per table column, see datagen.Spec
+	Fields map[string]datagen.Spec `json:"fields"`
+}
+
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	file, err := os.Open(path)
+	if err != nil {
+		return config, err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Dataset is the profile name or, if name is empty, the profile of the config
+// file. rows are the rows per table of the custom profile if the config file
+// does not define it.
+func (c Config) Dataset(name string, rows int) (Profile, error) {
+	if name == "" {
+		name = c.Profile
+	}
+	return ParseProfile(name, c.Profiles, rows)
+}
+
+// Generator creates the data generator configured by the config, every value
+// and key of the run is derived from seed.
+func (c Config) Generator(seed int64) (*datagen.Generator, error) {
+	return datagen.New(seed, c.Fields)
+}
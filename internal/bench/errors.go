@@ -0,0 +1,226 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type ErrorClass string
+
+const (
+	ConstraintViolation  ErrorClass = "constraint violation"
+	DuplicateKey         ErrorClass = "duplicate key"
+	Timeout              ErrorClass = "timeout"
+	ConnectionReset      ErrorClass = "connection reset"
+	SerializationFailure ErrorClass = "serialization failure"
+	OtherError           ErrorClass = "other"
+)
+
+// Classifier maps a driver error to its class, every backend brings its own.
+type Classifier func(err error) ErrorClass
+
+// OnError is what a run does when an operation fails.
+type OnError string
+
+const (
+	// Abort stops the run on the first failed operation
+	Abort OnError = "abort"
+	// Continue counts the failed operation and goes on with the next one
+	Continue OnError = "continue"
+	// Retry repeats the failed operation, even if the error is not transient,
+	// before counting it and going on
+	Retry OnError = "retry"
+)
+
+func ParseOnError(value string) (OnError, error) {
+	switch onError := OnError(value); onError {
+	case Abort, Continue, Retry:
+		return onError, nil
+	}
+	return "", fmt.Errorf("unknown error policy %q, expected abort, continue or retry", value)
+}
+
+type ErrorPolicy struct {
+	OnError OnError
+	// MaxErrors is the error budget of the whole run, 0 means unlimited
+	MaxErrors int64
+	Retry     RetryPolicy
+}
+
+// OpStats are the counters of the operations run through Errors.Do during a scenario.
+type OpStats struct {
+	Ops    int64
+	Errors map[ErrorClass]int64
+	// Retries is the number of repeated attempts
+	Retries int64
+	// FirstAttempt is the total latency of the first attempts of all operations
+	FirstAttempt time.Duration
+	// RetryLatency is the extra latency added by the retries, backoff included
+	RetryLatency time.Duration
+}
+
+// Errors runs the operations of a run, retries the transient failures,
+// counts the remaining ones per class and aborts the run once the policy
+// says so. It is safe for concurrent use by the workers.
+type Errors struct {
+	mx        sync.Mutex
+	policy    ErrorPolicy
+	classify  Classifier
+	retryable Retryable
+	abort     context.CancelFunc
+
+	ops          int64
+	retries      int64
+	firstAttempt int64
+	retryLatency int64
+
+	total  int64
+	counts map[ErrorClass]int64
+	err    error
+}
+
+// NewErrors creates the error tracker of a run, abort cancels the run context.
+func NewErrors(policy ErrorPolicy, classify Classifier, retryable Retryable, abort context.CancelFunc) *Errors {
+	return &Errors{
+		policy:    policy,
+		classify:  classify,
+		retryable: retryable,
+		abort:     abort,
+		counts:    map[ErrorClass]int64{},
+	}
+}
+
+// Do runs op and repeats it with a jittered exponential backoff while it fails
+// with a retryable error (any error with the Retry policy), the error is
+// recorded if it still fails. Failures caused by the cancellation of ctx are
+// not counted.
+func (e *Errors) Do(ctx context.Context, op func() error) error {
+	start := time.Now()
+	err := op()
+	atomic.AddInt64(&e.firstAttempt, int64(time.Since(start)))
+	atomic.AddInt64(&e.ops, 1)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+
+	retryStart := time.Now()
+	for attempt := 0; attempt < e.policy.Retry.Retries && e.shouldRetry(err); attempt++ {
+		if sleep(ctx, e.policy.Retry.backoff(attempt)) != nil {
+			break
+		}
+		atomic.AddInt64(&e.retries, 1)
+		if err = op(); err == nil {
+			break
+		}
+	}
+	atomic.AddInt64(&e.retryLatency, int64(time.Since(retryStart)))
+
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	e.Record(err)
+	return err
+}
+
+func (e *Errors) shouldRetry(err error) bool {
+	return e.policy.OnError == Retry || e.retryable(err)
+}
+
+func (e *Errors) Record(err error) {
+	class := e.classify(err)
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	e.counts[class]++
+	e.total++
+	if e.counts[class] == 1 {
+		log.Printf("First %s error: %v", class, err)
+	}
+
+	if e.err != nil {
+		return
+	}
+	if e.policy.OnError == Abort {
+		e.err = fmt.Errorf("aborted on first error: %w", err)
+	} else if e.policy.MaxErrors > 0 && e.total > e.policy.MaxErrors {
+		e.err = fmt.Errorf("error budget of %d exceeded, last error: %w", e.policy.MaxErrors, err)
+	}
+	if e.err != nil {
+		log.Printf("Stopping the run: %v", e.err)
+		e.abort()
+	}
+}
+
+// Fail stops the run with err, e.g. when the loaded data does not match the request.
+func (e *Errors) Fail(err error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	if e.err == nil {
+		e.err = err
+		log.Printf("Stopping the run: %v", err)
+		e.abort()
+	}
+}
+
+// Take returns the counters recorded since the previous call, i.e. the
+// operations of the scenario that has just finished.
+func (e *Errors) Take() OpStats {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	stats := OpStats{
+		Ops:          atomic.SwapInt64(&e.ops, 0),
+		Errors:       e.counts,
+		Retries:      atomic.SwapInt64(&e.retries, 0),
+		FirstAttempt: time.Duration(atomic.SwapInt64(&e.firstAttempt, 0)),
+		RetryLatency: time.Duration(atomic.SwapInt64(&e.retryLatency, 0)),
+	}
+	e.counts = map[ErrorClass]int64{}
+	return stats
+}
+
+// Err is the reason the run was aborted, nil if it was not.
+func (e *Errors) Err() error {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	return e.err
+}
+
+// ClassifyTransport recognises the network level errors every driver can
+// return, it reports OtherError for anything else.
+func ClassifyTransport(err error) ErrorClass {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return Timeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return Timeout
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ConnectionReset
+	}
+	return OtherError
+}
+
+func sortedClasses(counts map[ErrorClass]int64) []ErrorClass {
+	classes := make([]ErrorClass, 0, len(counts))
+	for class := range counts {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i] < classes[j]
+	})
+	return classes
+}
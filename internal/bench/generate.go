@@ -0,0 +1,79 @@
+package bench
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/internal/fixture"
+	"strings"
+	"time"
+)
+
+// Row is the row of table at position the insert scenarios load, in the
+// order of fixture.Columns
+func (p Profile) Row(data *datagen.Generator, table string, position int) []interface{} {
+	switch table {
+	case "users":
+		return []interface{}{position, data.Text("users.name", position), data.Text("users.description", position)}
+	case "articles":
+		return []interface{}{position, p.AuthorOf(position), data.Text("articles.title", position), data.Text("articles.text", position)}
+	}
+	return []interface{}{position, p.CommenterOf(position), p.ArticleOf(position), data.Text("comments.title", position), data.Text("comments.text", position)}
+}
+
+// Generate writes the rows of the users, articles and comments of profile to
+// files in dir, e.g. users.csv, without a database. They are the rows the
+// insert scenarios load with the seed of data.
+func Generate(dir, format string, profile Profile, data *datagen.Generator) error {
+	if _, ok := fixture.Extensions[format]; !ok {
+		return fmt.Errorf("unknown format %q, expected csv, jsonl or copy", format)
+	}
+	// the files have no NULL
+	if err := data.CheckNullable(nil); err != nil {
+		return err
+	}
+	profile = profile.WithData(data)
+	counts := map[string]int{"users": profile.Users, "articles": profile.Articles(), "comments": profile.Comments()}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, table := range fixture.Tables {
+		start := time.Now()
+		path := filepath.Join(dir, table+fixture.Extensions[format])
+		log.Printf("========== GENERATE %s ============", strings.ToUpper(table))
+		log.Printf("Generate %d %s to %s in progress...", counts[table], table, path)
+
+		if err := generateTable(path, format, table, counts[table], profile, data); err != nil {
+			return err
+		}
+
+		log.Printf("Generated %d rows in %s", counts[table], time.Since(start))
+		log.Print("==============================")
+	}
+	return nil
+}
+
+func generateTable(path, format, table string, rows int, profile Profile, data *datagen.Generator) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := fixture.NewWriter(file, table, format)
+	if err != nil {
+		return err
+	}
+	for position := 0; position < rows; position++ {
+		if err := writer.Write(profile.Row(data, table, position)); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
+}
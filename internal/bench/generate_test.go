@@ -0,0 +1,62 @@
+package bench
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/internal/fixture"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateWritesTheLoadedRows(t *testing.T) {
+	dir := t.TempDir()
+	profile := skewed(Profile{Name: "test", Users: 20, ArticlesPerUser: 3, CommentsPerArticle: 2})
+	data, err := datagen.New(5, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Generate(dir, fixture.CSV, profile, data); err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := fixture.Find(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile = profile.WithData(data)
+	for table, rows := range map[string]int{"users": 20, "articles": 60, "comments": 120} {
+		reader, err := fixture.Open(table, sources[table])
+		if err != nil {
+			t.Fatal(err)
+		}
+		position := 0
+		for ; ; position++ {
+			row, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := profile.Row(data, table, position); !reflect.DeepEqual(row, want) {
+				t.Fatalf("%s %d: %v, want %v", table, position, row, want)
+			}
+		}
+		reader.Close()
+		if position != rows {
+			t.Fatalf("%s has %d rows, want %d", table, position, rows)
+		}
+	}
+}
+
+func TestGenerateRejectsUnknownFormat(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	if err := Generate(dir, "xml", CustomProfile(1), datagen.Default()); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("the directory was created: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"postgres_performance_test/internal/fixture"
+	"strings"
+	"time"
+)
+
+// Importer is a backend that loads the tables from fixture files with its
+// fastest bulk path instead of generating the rows.
+type Importer interface {
+	// Import loads the rows of table, it adds them to the expected rows and
+	// the checksums like the insert scenarios do
+	Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error)
+}
+
+// loadScenarios are the scenarios of the built-in backends that generate the
+// dataset, an import replaces them
+var loadScenarios = map[string]bool{
+	"insert users":                       true,
+	"insert articles":                    true,
+	"insert articles without references": true,
+	"insert comments":                    true,
+	"insert comments without references": true,
+}
+
+// Import is the scenario loading table from source. A failed import is not
+// retried, the rows it loaded are in the table, so it stops the run.
+func Import(importer Importer, table string, source fixture.Source) Scenario {
+	return Scenario{
+		Name: "import " + table,
+		Run: func(ctx context.Context, errs *Errors) int64 {
+			start := time.Now()
+			log.Printf("========== IMPORT %s ============", strings.ToUpper(table))
+			log.Printf("Import %s from %s in progress...", table, source.Path)
+
+			imported, err := importRows(ctx, importer, table, source)
+			if err != nil {
+				if ctx.Err() == nil {
+					// a retry would load the rows imported before the error again
+					errs.Record(err)
+					errs.Fail(fmt.Errorf("import %s: %w", table, err))
+				}
+				return imported
+			}
+
+			log.Printf("Imported %d rows in %s", imported, time.Since(start))
+			log.Print("==============================")
+			return imported
+		},
+	}
+}
+
+// importRows opens source and imports its rows into table
+func importRows(ctx context.Context, importer Importer, table string, source fixture.Source) (int64, error) {
+	rows, err := fixture.Open(table, source)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	return importer.Import(ctx, table, rows)
+}
+
+// importing replaces the scenarios that generate the dataset with the imports
+// of sources, the other scenarios then run against the imported rows
+func importing(backend Backend, scenarios []Scenario, sources fixture.Sources) ([]Scenario, error) {
+	importer, ok := backend.(Importer)
+	if !ok {
+		return nil, fmt.Errorf("%s can not import fixtures", backend.Name())
+	}
+
+	loads := map[string]bool{}
+	for _, scenario := range scenarios {
+		if loadScenarios[scenario.Name] {
+			loads[scenario.Name] = true
+		}
+	}
+
+	var imports []Scenario
+	for _, table := range sources.Tables() {
+		scenario := Import(importer, table, sources[table])
+		// the tables the backend does not load are not imported either
+		if len(loads) > 0 && !loads["insert "+table] {
+			scenario = Unsupported(scenario, fmt.Sprintf("%s keeps no %s", backend.Name(), table))
+		}
+		imports = append(imports, scenario)
+	}
+
+	var replaced []Scenario
+	for _, scenario := range scenarios {
+		if !loadScenarios[scenario.Name] {
+			replaced = append(replaced, scenario)
+		} else if imports != nil {
+			replaced = append(replaced, imports...)
+			imports = nil
+		}
+	}
+	return append(imports, replaced...), nil
+}
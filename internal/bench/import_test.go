@@ -0,0 +1,58 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"postgres_performance_test/internal/fixture"
+	"strings"
+	"testing"
+)
+
+func TestImportNeedsAnImporter(t *testing.T) {
+	backend := &scriptedBackend{}
+	backend.scenarios = []Scenario{backend.scenario("insert users", nil), backend.scenario("select users by id", nil)}
+	options := Options{Fixtures: fixture.Sources{"users": {Path: "users.csv"}}}
+
+	report := NewRunner(backend, options).Run(context.Background())
+	if report.Err == nil || !strings.Contains(report.Err.Error(), "can not import") {
+		t.Fatalf("unexpected error %v", report.Err)
+	}
+	if len(backend.ran) != 0 || !backend.tornDown {
+		t.Fatalf("ran %v, torn down %v", backend.ran, backend.tornDown)
+	}
+}
+
+// failingImporter is a scripted backend whose imports fail after the first row
+type failingImporter struct {
+	scriptedBackend
+	imports int
+}
+
+func (f *failingImporter) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	f.imports++
+	if _, err := rows.Next(); err != nil {
+		return 0, err
+	}
+	return 1, errFake
+}
+
+func TestFailedImportIsNotRetried(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(path, []byte("id,name,description\n1,a,b\n2,c,d\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &failingImporter{}
+	backend.scenarios = []Scenario{backend.scenario("insert users", nil), backend.scenario("select users by id", nil)}
+	options := Options{Fixtures: fixture.Sources{"users": {Path: path}}, ErrorPolicy: ErrorPolicy{OnError: Retry, Retry: RetryPolicy{Retries: 3}}}
+
+	report := NewRunner(backend, options).Run(context.Background())
+	if backend.imports != 1 {
+		t.Fatalf("the import ran %d times", backend.imports)
+	}
+	if report.Err == nil || !errors.Is(report.Err, errFake) || len(backend.ran) != 0 {
+		t.Fatalf("the run went on after the failed import: %v, ran %v", report.Err, backend.ran)
+	}
+}
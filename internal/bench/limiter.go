@@ -0,0 +1,62 @@
+package bench
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter spaces the operations of the workers evenly, so a scenario runs at
+// a fixed rate instead of as fast as the database allows.
+type Limiter struct {
+	mx       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewLimiter creates the limiter of rate operations per second, nil if rate
+// is not positive, which does not limit.
+func NewLimiter(rate float64) *Limiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &Limiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// Wait blocks until the next operation may start or ctx is cancelled. The
+// slots are not saved up while nobody waits, so there are no bursts.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mx.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	start := l.next
+	l.next = l.next.Add(l.interval)
+	l.mx.Unlock()
+
+	if d := time.Until(start); d > 0 {
+		return sleep(ctx, d)
+	}
+	return ctx.Err()
+}
+
+type limiterKey struct{}
+
+// withLimiter makes the worker pools started with ctx wait for l
+func withLimiter(ctx context.Context, l *Limiter) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+// limiter is the limiter of ctx, nil if the operations are not limited
+func limiter(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(limiterKey{}).(*Limiter)
+	return l
+}
@@ -0,0 +1,70 @@
+package bench
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterSpacesTheOperationsOfAllWorkers(t *testing.T) {
+	ctx := withLimiter(context.Background(), NewLimiter(200))
+
+	start := time.Now()
+	metrics := RunWorkers(ctx, 21, 4, func(position int) error { return nil })
+	elapsed := time.Since(start)
+
+	// 21 operations at 200/s start 5ms apart, the first at once
+	if metrics.Done != 21 || elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Fatalf("%d operations in %s", metrics.Done, elapsed)
+	}
+	// the wait for the limiter is not part of the latency
+	if metrics.Max >= 5*time.Millisecond {
+		t.Fatalf("max latency %s includes the wait", metrics.Max)
+	}
+}
+
+func TestLimiterWithoutRate(t *testing.T) {
+	if l := NewLimiter(0); l != nil {
+		t.Fatalf("rate 0 is limited by %+v", l)
+	}
+
+	ctx := withLimiter(context.Background(), NewLimiter(0))
+	start := time.Now()
+	if metrics := RunWorkers(ctx, 1000, 2, func(position int) error { return nil }); metrics.Done != 1000 {
+		t.Fatalf("%d operations done", metrics.Done)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("unlimited operations took %s", elapsed)
+	}
+}
+
+func TestLimiterWaitStopsOnCancel(t *testing.T) {
+	l := NewLimiter(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next slot is a second away
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := l.Wait(ctx); err != context.Canceled || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("Wait returned %v after %s", err, time.Since(start))
+	}
+}
+
+func TestRunnerLimitsTheRate(t *testing.T) {
+	backend := &scriptedBackend{}
+	var elapsed time.Duration
+	backend.scenarios = []Scenario{{Name: "limited", Run: func(ctx context.Context, errs *Errors) int64 {
+		start := time.Now()
+		metrics := RunWorkers(ctx, 11, 2, func(position int) error { return nil })
+		elapsed = time.Since(start)
+		return metrics.Done
+	}}}
+
+	report := NewRunner(backend, Options{Rate: 100}).Run(context.Background())
+	if report.Err != nil || elapsed < 90*time.Millisecond {
+		t.Fatalf("11 operations at 100/s took %s: %v", elapsed, report.Err)
+	}
+}
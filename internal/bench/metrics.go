@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Collector records the operations of a single worker. It is not safe for
+// concurrent use: every worker owns its collector and the collectors are only
+// merged once all workers are done.
+type Collector struct {
+	Done      int64
+	Failed    int64
+	Latencies []time.Duration
+	Elapsed   time.Duration
+}
+
+// Observe records one operation.
+func (c *Collector) Observe(latency time.Duration, err error) {
+	if err != nil {
+		c.Failed++
+		return
+	}
+	c.Done++
+	c.Latencies = append(c.Latencies, latency)
+}
+
+// Metrics are the merged collectors of all workers of a scenario.
+type Metrics struct {
+	Workers int
+	Done    int64
+	Failed  int64
+	// RPS is the sum of the rates of the workers, each over its own elapsed time
+	RPS float64
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+// Merge combines the collectors of the workers, the collectors must not be
+// used by the workers any more.
+func Merge(collectors []*Collector) Metrics {
+	metrics := Metrics{Workers: len(collectors)}
+
+	var latencies []time.Duration
+	for _, c := range collectors {
+		metrics.Done += c.Done
+		metrics.Failed += c.Failed
+		if seconds := c.Elapsed.Seconds(); seconds > 0 {
+			metrics.RPS += float64(c.Done) / seconds
+		}
+		latencies = append(latencies, c.Latencies...)
+	}
+
+	if len(latencies) == 0 {
+		return metrics
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	metrics.P50 = percentile(latencies, 50)
+	metrics.P95 = percentile(latencies, 95)
+	metrics.P99 = percentile(latencies, 99)
+	metrics.Max = latencies[len(latencies)-1]
+	return metrics
+}
+
+// percentile of sorted latencies, nearest rank
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// recorder collects the collectors of the worker pools a scenario starts,
+// Measure puts it in the context of the scenario
+type recorder struct {
+	mx         sync.Mutex
+	collectors []*Collector
+}
+
+type recorderKey struct{}
+
+func withRecorder(ctx context.Context) (context.Context, *recorder) {
+	r := &recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// record adds the collectors of a worker pool to the recorder of ctx, if any
+func record(ctx context.Context, collectors []*Collector) {
+	r, ok := ctx.Value(recorderKey{}).(*recorder)
+	if !ok {
+		return
+	}
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.collectors = append(r.collectors, collectors...)
+}
+
+// metrics merges the collectors of all pools, nil if the scenario started none
+func (r *recorder) metrics() *Metrics {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if len(r.collectors) == 0 {
+		return nil
+	}
+	metrics := Merge(r.collectors)
+	return &metrics
+}
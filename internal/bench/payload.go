@@ -0,0 +1,91 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PayloadSizes are the text sizes of a full payload sweep, from values
+// that fit in a page past the Postgres TOAST threshold (about 2 KB) up to
+// documents of a megabyte.
+var PayloadSizes = []int{100, 2 << 10, 8 << 10, 64 << 10, 1 << 20}
+
+// payloadBudget caps the bytes inserted per payload size, so the large sizes
+// insert fewer rows instead of filling the database
+const payloadBudget = 64 << 20
+
+// PayloadRows is the number of rows the sweep inserts with payloads of size,
+// rows at most and at least one.
+func PayloadRows(rows, size int) int {
+	if size > 0 && rows > payloadBudget/size {
+		rows = payloadBudget / size
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return rows
+}
+
+// PayloadSweep are the scenarios of the payload-size sweep: for every size
+// the table is prepared by a check, then rows with payloads of the size are
+// inserted and read, the insert reports the storage the rows take. drop
+// removes the table after the last size.
+func PayloadSweep(sizes []int, prepare, drop func(ctx context.Context, errs *Errors), insert, read func(size int) Scenario) []Scenario {
+	var scenarios []Scenario
+	for _, size := range sizes {
+		scenarios = append(scenarios,
+			Check("prepare payload "+FormatBytes(int64(size)), prepare),
+			insert(size),
+			read(size),
+		)
+	}
+	if len(scenarios) > 0 {
+		scenarios = append(scenarios, Check("drop payload table", drop))
+	}
+	return scenarios
+}
+
+// LogPayloadMetrics logs the throughput of a payload scenario in rows and bytes
+func LogPayloadMetrics(metrics Metrics, size int, elapsed time.Duration) {
+	log.Printf("Average RPS for %d rows = %.0f, %s/s", metrics.Done, metrics.RPS, FormatBytes(int64(metrics.RPS*float64(size))))
+	log.Printf("Latency p50 = %s, p95 = %s, p99 = %s", metrics.P50, metrics.P95, metrics.P99)
+	log.Printf("Passed in %s", elapsed)
+	log.Print("==============================")
+}
+
+// ParseSizes parses a comma separated list of sizes like "100,2KB,1MB", the
+// units are powers of 1024.
+func ParseSizes(value string) ([]int, error) {
+	var sizes []int
+	for _, raw := range strings.Split(value, ",") {
+		field := strings.ToUpper(strings.TrimSpace(raw))
+		if field == "" {
+			continue
+		}
+
+		unit := 1
+		for _, suffix := range []struct {
+			name string
+			unit int
+		}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"K", 1 << 10}, {"M", 1 << 20}, {"B", 1}} {
+			if strings.HasSuffix(field, suffix.name) {
+				field, unit = strings.TrimSpace(strings.TrimSuffix(field, suffix.name)), suffix.unit
+				break
+			}
+		}
+
+		n, err := strconv.Atoi(field)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid payload size %q", strings.TrimSpace(raw))
+		}
+		sizes = append(sizes, n*unit)
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("no payload sizes in %q", value)
+	}
+	return sizes, nil
+}
@@ -0,0 +1,81 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSizes(t *testing.T) {
+	sizes, err := ParseSizes("100, 2KB,8k,1MB,3b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{100, 2048, 8192, 1 << 20, 3}; !reflect.DeepEqual(sizes, want) {
+		t.Fatalf("sizes %v, want %v", sizes, want)
+	}
+
+	for _, value := range []string{"", "2GB", "-1", "0", "1.5KB"} {
+		if _, err := ParseSizes(value); err == nil {
+			t.Fatalf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestPayloadRowsStayInBudget(t *testing.T) {
+	if got := PayloadRows(1000, 100); got != 1000 {
+		t.Fatalf("small payloads got %d rows", got)
+	}
+	if got := PayloadRows(1000, 1<<20); got != 64 {
+		t.Fatalf("1 MB payloads got %d rows", got)
+	}
+	if got := PayloadRows(1000, 1<<30); got != 1 {
+		t.Fatalf("1 GB payloads got %d rows", got)
+	}
+}
+
+func TestPayloadSweepOrder(t *testing.T) {
+	noop := func(ctx context.Context, errs *Errors) {}
+	scenario := func(verb string) func(size int) Scenario {
+		return func(size int) Scenario {
+			return Scenario{Name: verb + " payload " + FormatBytes(int64(size))}
+		}
+	}
+
+	var names []string
+	for _, scenario := range PayloadSweep([]int{100, 2048}, noop, noop, scenario("insert"), scenario("select")) {
+		names = append(names, scenario.Name)
+	}
+	want := "prepare payload 100 B,insert payload 100 B,select payload 100 B," +
+		"prepare payload 2.0 KiB,insert payload 2.0 KiB,select payload 2.0 KiB,drop payload table"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("scenarios %s", got)
+	}
+	if len(PayloadSweep(nil, noop, noop, scenario("insert"), scenario("select"))) != 0 {
+		t.Fatal("a sweep without sizes has scenarios")
+	}
+}
+
+func TestRunnerReportsStorageOfScenario(t *testing.T) {
+	backend := &scriptedBackend{}
+	measured := backend.scenario("measured", nil)
+	measured.Storage = func(ctx context.Context) (TableSize, error) {
+		return TableSize{Name: "payloads", Heap: 100, Toast: 900, Total: 1000}, nil
+	}
+	failing := backend.scenario("failing", nil)
+	failing.Storage = func(ctx context.Context) (TableSize, error) {
+		return TableSize{}, errors.New("no statistics")
+	}
+	backend.scenarios = []Scenario{measured, failing}
+
+	report := NewRunner(backend, Options{}).Run(context.Background())
+
+	if storage := report.Results[0].Storage; storage == nil || storage.Toast != 900 || storage.Total != 1000 {
+		t.Fatalf("unexpected storage %+v", storage)
+	}
+	if report.Results[1].Storage != nil || report.Err != nil {
+		t.Fatalf("a failed storage measurement should only be logged, got %+v", report)
+	}
+}
@@ -0,0 +1,215 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"postgres_performance_test/internal/datagen"
+	"strings"
+)
+
+// Profile is the size and the shape of the dataset of a run: the number of
+// users, the fan-out of articles per user and comments per article and how
+// the references are distributed.
+type Profile struct {
+	Name               string  `json:"-"`
+	Users              int     `json:"users"`
+	ArticlesPerUser    float64 `json:"articles_per_user"`
+	CommentsPerArticle float64 `json:"comments_per_article"`
+	// Authors distributes the articles over the users
+	Authors Distribution `json:"authors"`
+	// Commented distributes the comments over the articles
+	Commented Distribution `json:"commented"`
+	// Commenters distributes the comments over the users
+	Commenters Distribution `json:"commenters"`
+	// OrphanFree gives every user at least one article and every article at
+	// least one comment as long as there are enough of them, the skewed
+	// distributions leave most of them without any otherwise
+	OrphanFree bool `json:"orphan_free"`
+
+	// data picks the references of the skewed distributions
+	data *datagen.Generator
+}
+
+// Custom is the profile of the config file or, without one, of the row count
+// entered for the run
+const Custom = "custom"
+
+// Skewed ends the names of the built-in profiles with skewed references
+const Skewed = "-skewed"
+
+// Profiles are the built-in profiles, large is the former test schema of 100k
+// users, 1 m articles and 10 m comments. Their references are spread evenly,
+// so the results stay comparable to the runs before the distributions. Every
+// profile has a skewed variant, e.g. large-skewed, whose authors and
+// commenters follow a power law and where half of the comments go to 1% of
+// the articles.
+var Profiles = withSkewed(map[string]Profile{
+	"tiny":   {Name: "tiny", Users: 100, ArticlesPerUser: 5, CommentsPerArticle: 5},
+	"small":  {Name: "small", Users: 1000, ArticlesPerUser: 10, CommentsPerArticle: 10},
+	"medium": {Name: "medium", Users: 10000, ArticlesPerUser: 10, CommentsPerArticle: 10},
+	"large":  {Name: "large", Users: 100000, ArticlesPerUser: 10, CommentsPerArticle: 10},
+})
+
+// withSkewed adds the skewed variant of every profile
+func withSkewed(profiles map[string]Profile) map[string]Profile {
+	all := map[string]Profile{}
+	for name, p := range profiles {
+		all[name] = p
+		p.Name = name + Skewed
+		all[p.Name] = skewed(p)
+	}
+	return all
+}
+
+func skewed(p Profile) Profile {
+	p.Authors = Distribution{Kind: Zipf, Exponent: 1}
+	p.Commented = Distribution{Kind: Viral, Share: 0.01, Weight: 0.5}
+	p.Commenters = Distribution{Kind: Zipf, Exponent: 1}
+	p.OrphanFree = true
+	return p
+}
+
+// ProfileNames are the names of the profiles in the order of their size, the
+// skewed ones after the uniform ones
+var ProfileNames = []string{"tiny", "small", "medium", "large", "tiny" + Skewed, "small" + Skewed, "medium" + Skewed, "large" + Skewed, Custom}
+
+// CustomProfile has rows users, articles and comments, every table gets the
+// same number of rows and the references are spread evenly.
+func CustomProfile(rows int) Profile {
+	return Profile{Name: Custom, Users: rows, ArticlesPerUser: 1, CommentsPerArticle: 1}
+}
+
+func (p Profile) Articles() int {
+	return int(math.Round(float64(p.Users) * p.ArticlesPerUser))
+}
+
+func (p Profile) Comments() int {
+	return int(math.Round(float64(p.Articles()) * p.CommentsPerArticle))
+}
+
+// AuthorOf is the user who wrote the article at position. Positions past the
+// articles of the profile wrap around, so benchmarks can insert more rows than
+// were loaded.
+func (p Profile) AuthorOf(article int) int {
+	return p.reference("articles.author_id", p.Authors, article, p.Articles(), p.Users)
+}
+
+// ArticleOf is the article the comment at position belongs to
+func (p Profile) ArticleOf(comment int) int {
+	return p.reference("comments.article_id", p.Commented, comment, p.Comments(), p.Articles())
+}
+
+// CommenterOf is the user who wrote the comment at position
+func (p Profile) CommenterOf(comment int) int {
+	return p.reference("comments.author_id", p.Commenters, comment, p.Comments(), p.Users)
+}
+
+// WithData makes the skewed references depend on the seed of data
+func (p Profile) WithData(data *datagen.Generator) Profile {
+	p.data = data
+	return p
+}
+
+// reference is the target of [0, to) of the row at position of [0, from),
+// it depends on the seed, the column and the position only
+func (p Profile) reference(column string, distribution Distribution, position, from, to int) int {
+	if from <= 0 || to <= 0 {
+		return 0
+	}
+	position %= from
+	if distribution.uniform() {
+		return spread(position, from, to)
+	}
+	// the first rows reference every target once
+	if p.OrphanFree && position < to {
+		return position
+	}
+
+	data := p.data
+	if data == nil {
+		data = defaultData
+	}
+	return distribution.pick(data.Float64(column, position), to)
+}
+
+var defaultData = datagen.Default()
+
+// spread maps position of [0, from) onto [0, to) keeping the order, so every
+// target gets the same number of positions give or take one
+func spread(position, from, to int) int {
+	return int(int64(position) * int64(to) / int64(from))
+}
+
+// Imported is the profile after rows of table were imported, so the reads
+// pick their keys among the imported rows. The ids of the files should be
+// dense from 0 for that.
+func (p Profile) Imported(table string, rows int64) Profile {
+	if rows <= 0 {
+		return p
+	}
+	users, articles, comments := float64(p.Users), float64(p.Articles()), float64(p.Comments())
+	switch table {
+	case "users":
+		users = float64(rows)
+	case "articles":
+		articles = float64(rows)
+	case "comments":
+		comments = float64(rows)
+	}
+
+	p.Name = "imported"
+	p.Users = int(users)
+	p.ArticlesPerUser = articles / users
+	p.CommentsPerArticle = comments / articles
+	return p
+}
+
+func (p Profile) Validate() error {
+	if p.Users <= 0 {
+		return fmt.Errorf("profile %s: users must be positive", p.Name)
+	}
+	if p.ArticlesPerUser <= 0 || p.CommentsPerArticle <= 0 {
+		return fmt.Errorf("profile %s: articles_per_user and comments_per_article must be positive", p.Name)
+	}
+	if p.Articles() == 0 || p.Comments() == 0 {
+		return fmt.Errorf("profile %s: the ratios leave no articles or comments", p.Name)
+	}
+	for name, distribution := range map[string]Distribution{"authors": p.Authors, "commented": p.Commented, "commenters": p.Commenters} {
+		if err := distribution.validate(); err != nil {
+			return fmt.Errorf("profile %s: %s: %w", p.Name, name, err)
+		}
+	}
+	return nil
+}
+
+func (p Profile) String() string {
+	s := fmt.Sprintf("%s (%d users, %d articles, %d comments)", p.Name, p.Users, p.Articles(), p.Comments())
+	if p.Authors.uniform() && p.Commented.uniform() && p.Commenters.uniform() {
+		return s
+	}
+	s += fmt.Sprintf(", authors %s, commented %s, commenters %s", p.Authors, p.Commented, p.Commenters)
+	if p.OrphanFree {
+		s += ", orphan-free"
+	}
+	return s
+}
+
+// ParseProfile looks up the profile name, the profiles of the config file
+// take precedence over the built-in ones. custom without a profile in the
+// config file is CustomProfile(rows).
+func ParseProfile(name string, defined map[string]Profile, rows int) (Profile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	profile, ok := defined[name]
+	if !ok {
+		profile, ok = Profiles[name]
+	}
+	if !ok && name == Custom {
+		profile, ok = CustomProfile(rows), true
+	}
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q, expected one of %s", name, strings.Join(ProfileNames, ", "))
+	}
+
+	profile.Name = name
+	return profile, profile.Validate()
+}
@@ -0,0 +1,107 @@
+package bench
+
+import (
+	"testing"
+)
+
+func TestProfileCounts(t *testing.T) {
+	large := Profiles["large"]
+	if large.Users != 100000 || large.Articles() != 1000000 || large.Comments() != 10000000 {
+		t.Fatalf("unexpected large profile %s", large)
+	}
+	if !large.Authors.uniform() || !large.Commented.uniform() || !large.Commenters.uniform() || large.OrphanFree {
+		t.Fatalf("the built-in profile is skewed: %s", large)
+	}
+
+	skewedLarge := Profiles["large"+Skewed]
+	if skewedLarge.Name != "large"+Skewed || skewedLarge.Comments() != large.Comments() || skewedLarge.Authors.uniform() || !skewedLarge.OrphanFree {
+		t.Fatalf("unexpected skewed large profile %s", skewedLarge)
+	}
+	for _, name := range ProfileNames {
+		if _, ok := Profiles[name]; !ok && name != Custom {
+			t.Fatalf("profile %s is not defined", name)
+		}
+	}
+
+	custom := CustomProfile(300)
+	if custom.Users != 300 || custom.Articles() != 300 || custom.Comments() != 300 {
+		t.Fatalf("unexpected custom profile %s", custom)
+	}
+
+	fractional := Profile{Name: "fractional", Users: 10, ArticlesPerUser: 2.5, CommentsPerArticle: 0.5}
+	if fractional.Articles() != 25 || fractional.Comments() != 13 {
+		t.Fatalf("unexpected fractional profile %s", fractional)
+	}
+}
+
+func TestProfileReferencesStayInRange(t *testing.T) {
+	profile := Profile{Name: "test", Users: 7, ArticlesPerUser: 3, CommentsPerArticle: 4}
+
+	perAuthor := map[int]int{}
+	for article := 0; article < profile.Articles(); article++ {
+		perAuthor[profile.AuthorOf(article)]++
+	}
+	for user := 0; user < profile.Users; user++ {
+		if perAuthor[user] != 3 {
+			t.Fatalf("user %d wrote %d articles, want 3", user, perAuthor[user])
+		}
+	}
+
+	for comment := 0; comment < profile.Comments(); comment++ {
+		if article := profile.ArticleOf(comment); article < 0 || article >= profile.Articles() {
+			t.Fatalf("comment %d references article %d", comment, article)
+		}
+		if user := profile.CommenterOf(comment); user < 0 || user >= profile.Users {
+			t.Fatalf("comment %d references user %d", comment, user)
+		}
+	}
+
+	// positions past the loaded rows wrap around
+	if got, want := profile.AuthorOf(profile.Articles()+4), profile.AuthorOf(4); got != want {
+		t.Fatalf("author of a later article %d, want %d", got, want)
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	profile, err := ParseProfile(" Small ", nil, 0)
+	if err != nil || profile.Name != "small" || profile.Users != 1000 {
+		t.Fatalf("unexpected profile %s, %v", profile, err)
+	}
+
+	defined := map[string]Profile{
+		"small":  {Users: 50, ArticlesPerUser: 2, CommentsPerArticle: 3},
+		"custom": {Users: 20, ArticlesPerUser: 1, CommentsPerArticle: 10},
+	}
+	if profile, err = ParseProfile("small", defined, 0); err != nil || profile.Users != 50 || profile.Comments() != 300 {
+		t.Fatalf("the config did not override small: %s, %v", profile, err)
+	}
+	if profile, err = ParseProfile(Custom, defined, 300); err != nil || profile.Users != 20 {
+		t.Fatalf("the config did not define custom: %s, %v", profile, err)
+	}
+	if profile, err = ParseProfile(Custom, nil, 300); err != nil || profile != CustomProfile(300) {
+		t.Fatalf("unexpected custom profile %s, %v", profile, err)
+	}
+
+	if _, err := ParseProfile("huge", nil, 0); err == nil {
+		t.Fatal("expected an error for an unknown profile")
+	}
+	if _, err := ParseProfile(Custom, nil, 0); err == nil {
+		t.Fatal("expected an error for a custom profile without rows")
+	}
+	if _, err := ParseProfile("broken", map[string]Profile{"broken": {Users: 10}}, 0); err == nil {
+		t.Fatal("expected an error for a profile without ratios")
+	}
+}
+
+func TestConfigDataset(t *testing.T) {
+	config := Config{Profile: "tiny"}
+	if profile, err := config.Dataset("", 0); err != nil || profile.Name != "tiny" {
+		t.Fatalf("unexpected profile %s, %v", profile, err)
+	}
+	if profile, err := config.Dataset("medium", 0); err != nil || profile.Name != "medium" {
+		t.Fatalf("the name did not override the config: %s, %v", profile, err)
+	}
+	if profile := (Options{Rows: 40}).Dataset(); profile.String() != CustomProfile(40).String() {
+		t.Fatalf("unexpected default profile %s", profile)
+	}
+}
@@ -0,0 +1,118 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+)
+
+// the kinds of Distribution
+const (
+	Uniform = "uniform"
+	Zipf    = "zipf"
+	Viral   = "viral"
+)
+
+// Distribution is how the rows of a table reference the rows of another one,
+// e.g. the articles their authors. Uniform spreads the rows evenly, zipf is a
+// power law where the first targets get most of the rows and viral sends
+// Weight of the rows to Share of the targets and spreads the rest evenly.
+type Distribution struct {
+	Kind string `json:"kind"`
+	// Exponent of zipf, 1 if it is not set, the larger the more skewed
+	Exponent float64 `json:"exponent,omitempty"`
+	// Share of the targets that are viral, 1% if it is not set
+	Share float64 `json:"share,omitempty"`
+	// Weight is the share of the rows referencing the viral targets, 50% if
+	// it is not set
+	Weight float64 `json:"weight,omitempty"`
+}
+
+func (d Distribution) uniform() bool {
+	return d.Kind == "" || d.Kind == Uniform
+}
+
+func (d Distribution) exponent() float64 {
+	if d.Exponent == 0 {
+		return 1
+	}
+	return d.Exponent
+}
+
+func (d Distribution) viral() (share, weight float64) {
+	share, weight = d.Share, d.Weight
+	if share == 0 {
+		share = 0.01
+	}
+	if weight == 0 {
+		weight = 0.5
+	}
+	return share, weight
+}
+
+// pick maps u of [0, 1) onto a target of [0, n)
+func (d Distribution) pick(u float64, n int) int {
+	var target int
+	switch d.Kind {
+	case Zipf:
+		// inverse of the CDF of the continuous power law over [1, n+1)
+		s, max := d.exponent(), float64(n+1)
+		var x float64
+		if s == 1 {
+			x = math.Pow(max, u)
+		} else {
+			x = math.Pow(1+u*(math.Pow(max, 1-s)-1), 1/(1-s))
+		}
+		target = int(x) - 1
+	case Viral:
+		share, weight := d.viral()
+		hot := int(math.Round(share * float64(n)))
+		if hot < 1 {
+			hot = 1
+		}
+		if hot >= n {
+			target = int(u * float64(n))
+		} else if u < weight {
+			target = int(u / weight * float64(hot))
+		} else {
+			target = hot + int((u-weight)/(1-weight)*float64(n-hot))
+		}
+	default:
+		target = int(u * float64(n))
+	}
+
+	if target < 0 {
+		return 0
+	}
+	if target >= n {
+		return n - 1
+	}
+	return target
+}
+
+func (d Distribution) validate() error {
+	switch d.Kind {
+	case "", Uniform:
+	case Zipf:
+		if d.Exponent < 0 {
+			return fmt.Errorf("zipf exponent must be positive")
+		}
+	case Viral:
+		if d.Share < 0 || d.Share >= 1 || d.Weight < 0 || d.Weight >= 1 {
+			return fmt.Errorf("viral share and weight must be in (0, 1)")
+		}
+	default:
+		return fmt.Errorf("unknown distribution %q, expected uniform, zipf or viral", d.Kind)
+	}
+	return nil
+}
+
+func (d Distribution) String() string {
+	switch d.Kind {
+	case Zipf:
+		return fmt.Sprintf("zipf %g", d.exponent())
+	case Viral:
+		share, weight := d.viral()
+		return fmt.Sprintf("viral %g%% get %g%%", share*100, weight*100)
+	}
+	return Uniform
+}
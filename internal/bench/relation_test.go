@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"postgres_performance_test/internal/datagen"
+	"testing"
+)
+
+func count(rows int, reference func(position int) int) map[int]int {
+	counts := map[int]int{}
+	for position := 0; position < rows; position++ {
+		counts[reference(position)]++
+	}
+	return counts
+}
+
+func TestZipfAuthorsAreSkewed(t *testing.T) {
+	profile := Profile{Name: "zipf", Users: 1000, ArticlesPerUser: 10, CommentsPerArticle: 1, Authors: Distribution{Kind: Zipf}}
+	articles := count(profile.Articles(), profile.AuthorOf)
+
+	for user := range articles {
+		if user < 0 || user >= profile.Users {
+			t.Fatalf("article of user %d out of range", user)
+		}
+	}
+	// the first user writes about 10% of the articles, a user in the tail hardly any
+	if articles[0] < 500 || articles[0] > 1500 || articles[900] > 20 {
+		t.Fatalf("unexpected articles per user: first %d, 900th %d", articles[0], articles[900])
+	}
+	if len(articles) == profile.Users {
+		t.Fatal("every user wrote an article without orphan_free")
+	}
+}
+
+func TestViralArticlesGetHalfOfTheComments(t *testing.T) {
+	profile := Profile{Name: "viral", Users: 100, ArticlesPerUser: 10, CommentsPerArticle: 20, Commented: Distribution{Kind: Viral}}
+	comments := count(profile.Comments(), profile.ArticleOf)
+
+	viral := 0
+	for article := 0; article < 10; article++ {
+		viral += comments[article]
+	}
+	if share := float64(viral) / float64(profile.Comments()); share < 0.45 || share > 0.55 {
+		t.Fatalf("viral articles got %.2f of the comments", share)
+	}
+}
+
+func TestOrphanFreeReferencesEveryTarget(t *testing.T) {
+	profile := skewed(Profile{Name: "orphans", Users: 500, ArticlesPerUser: 2, CommentsPerArticle: 3})
+
+	if articles := count(profile.Articles(), profile.AuthorOf); len(articles) != profile.Users {
+		t.Fatalf("%d of %d users wrote an article", len(articles), profile.Users)
+	}
+	if comments := count(profile.Comments(), profile.ArticleOf); len(comments) != profile.Articles() {
+		t.Fatalf("%d of %d articles got a comment", len(comments), profile.Articles())
+	}
+}
+
+func TestSkewedReferencesDependOnSeed(t *testing.T) {
+	profile := Profiles["small"+Skewed]
+	first, _ := datagen.New(1, nil)
+	again, _ := datagen.New(1, nil)
+	second, _ := datagen.New(2, nil)
+
+	differ := 0
+	for position := profile.Users; position < profile.Articles(); position++ {
+		author := profile.WithData(first).AuthorOf(position)
+		if repeated := profile.WithData(again).AuthorOf(position); repeated != author {
+			t.Fatalf("article %d: author %d, %d with the same seed", position, author, repeated)
+		}
+		if profile.WithData(second).AuthorOf(position) != author {
+			differ++
+		}
+	}
+	if differ == 0 {
+		t.Fatal("the authors do not depend on the seed")
+	}
+}
+
+func TestDistributionValidate(t *testing.T) {
+	for _, distribution := range []Distribution{{Kind: "pareto"}, {Kind: Zipf, Exponent: -1}, {Kind: Viral, Share: 1}, {Kind: Viral, Weight: 1.5}} {
+		if err := distribution.validate(); err == nil {
+			t.Fatalf("expected an error for %+v", distribution)
+		}
+	}
+	profile := Profile{Name: "broken", Users: 10, ArticlesPerUser: 1, CommentsPerArticle: 1, Commented: Distribution{Kind: "pareto"}}
+	if err := profile.Validate(); err == nil {
+		t.Fatal("expected an error for an unknown distribution")
+	}
+}
@@ -0,0 +1,199 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of a single scenario.
+type Result struct {
+	Name    string
+	Rows    int64
+	Elapsed time.Duration
+	// Partial is set when the scenario was interrupted before it could finish
+	Partial bool
+	// Skipped is set when the backend does not support the scenario, Note says why
+	Skipped bool
+	Note    string
+	OpStats
+	// Telemetry holds backend specific server-side counters, if any
+	Telemetry map[string]float64
+	// Storage is the footprint of the rows written by the scenario, if it
+	// measures it
+	Storage *TableSize `json:",omitempty"`
+	// Metrics are the merged rates and latencies of the workers of the
+	// scenario, if it runs them through RunWorkers or RunLoop
+	Metrics *Metrics `json:",omitempty"`
+}
+
+// Report collects the results of all scenarios of a run.
+type Report struct {
+	mx      sync.Mutex
+	Backend string
+	// Seed is the seed of the data and the keys, a run with the same seed
+	// repeats the same operations
+	Seed int64
+	// RunID names the schema or database of the run
+	RunID string
+	// Dataset is the profile of the loaded data
+	Dataset string
+	// Schema is the variant of the tables, empty for the default one
+	Schema  string
+	Results []Result
+	Partial bool
+	// Err is the reason the run was aborted by the error policy
+	Err error
+}
+
+func NewReport(backend string) *Report {
+	return &Report{Backend: backend}
+}
+
+// Measure runs scenario and times it, the result is marked partial when ctx
+// was cancelled while the scenario was running. The worker pools the scenario
+// starts with the context it gets add their metrics to the result.
+func Measure(ctx context.Context, name string, scenario func(ctx context.Context) int64) Result {
+	scenarioCtx, recorder := withRecorder(ctx)
+	start := time.Now()
+	rows := scenario(scenarioCtx)
+
+	return Result{
+		Name:    name,
+		Rows:    rows,
+		Elapsed: time.Since(start),
+		Partial: ctx.Err() != nil,
+		Metrics: recorder.metrics(),
+	}
+}
+
+func (r *Report) Add(result Result) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.Results = append(r.Results, result)
+	if result.Partial {
+		r.Partial = true
+	}
+}
+
+func (r *Report) MarkPartial() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.Partial = true
+}
+
+// Abort marks the run as stopped by the error policy
+func (r *Report) Abort(err error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	r.Partial = true
+	r.Err = err
+}
+
+func (r *Report) Log() {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if r.Partial {
+		log.Printf("========== %s RESULTS (PARTIAL) ==========", r.Backend)
+	} else {
+		log.Printf("========== %s RESULTS ==========", r.Backend)
+	}
+	log.Printf("Seed %d", r.Seed)
+	if r.RunID != "" {
+		log.Printf("Run %s", r.RunID)
+	}
+	if r.Dataset != "" {
+		log.Printf("Dataset %s", r.Dataset)
+	}
+	if r.Schema != "" {
+		log.Printf("Schema %s", r.Schema)
+	}
+	totals := map[ErrorClass]int64{}
+	for _, result := range r.Results {
+		status := ""
+		if result.Partial {
+			status = "partial"
+		} else if result.Skipped {
+			status = "skipped"
+		}
+		log.Printf("%-35s %12d rows %15s %s", result.Name, result.Rows, result.Elapsed, status)
+		if result.Note != "" {
+			log.Printf("%-35s %s", "", result.Note)
+		}
+		if result.Metrics != nil {
+			log.Printf("%-35s %12.0f rps, p50 %s, p95 %s, p99 %s", "", result.Metrics.RPS, result.Metrics.P50, result.Metrics.P95, result.Metrics.P99)
+		}
+		if result.Ops > 0 {
+			log.Printf("%-35s %12d ops, first attempt avg %s", "", result.Ops, result.FirstAttempt/time.Duration(result.Ops))
+		}
+		if result.Storage != nil {
+			perRow := ""
+			if result.Rows > 0 {
+				perRow = ", " + FormatBytes(result.Storage.Total/result.Rows) + " per row"
+			}
+			log.Printf("%-35s storage %s, toast %s, total %s%s", "", FormatBytes(result.Storage.Heap),
+				FormatBytes(result.Storage.Toast), FormatBytes(result.Storage.Total), perRow)
+		}
+		if result.Retries > 0 {
+			log.Printf("%-35s %12d retries, added %s", "", result.Retries, result.RetryLatency)
+		}
+		for _, class := range sortedClasses(result.Errors) {
+			log.Printf("%-35s %12d %s errors", "", result.Errors[class], class)
+			totals[class] += result.Errors[class]
+		}
+	}
+	if len(totals) > 0 {
+		log.Print("------- errors -------")
+		for _, class := range sortedClasses(totals) {
+			log.Printf("%-35s %12d", class, totals[class])
+		}
+	}
+	if r.Err != nil {
+		log.Printf("Run aborted: %v", r.Err)
+	}
+	log.Print("==============================")
+}
+
+// reportFile is the JSON of a report in the result file
+type reportFile struct {
+	Backend string
+	Seed    int64
+	RunID   string `json:",omitempty"`
+	Dataset string `json:",omitempty"`
+	Schema  string `json:",omitempty"`
+	Partial bool
+	Error   string `json:",omitempty"`
+	Results []Result
+}
+
+func (r *Report) file() reportFile {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	file := reportFile{Backend: r.Backend, Seed: r.Seed, RunID: r.RunID, Dataset: r.Dataset, Schema: r.Schema, Partial: r.Partial, Results: r.Results}
+	if r.Err != nil {
+		file.Error = r.Err.Error()
+	}
+	return file
+}
+
+// Save writes the report as JSON to the result file at path, durations are in
+// nanoseconds.
+func (r *Report) Save(path string) error {
+	return writeJSON(path, r.file())
+}
+
+func writeJSON(path string, value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,57 @@
+package bench
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Retryable reports whether a failed operation may succeed when repeated,
+// e.g. after a serialization failure or a failover. Every backend brings its own.
+type Retryable func(err error) bool
+
+type RetryPolicy struct {
+	// Retries is the maximum number of repetitions of a failed operation
+	Retries int
+	// Delay is the backoff before the first retry, it doubles with every retry
+	Delay    time.Duration
+	MaxDelay time.Duration
+}
+
+// maxBackoffShift caps the doublings of the delay, the later retries wait as
+// long as the last one
+const maxBackoffShift = 30
+
+// backoff returns the jittered delay before retry number attempt (starting at 0),
+// it is picked at random from the upper half of the exponential delay.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if attempt > maxBackoffShift {
+		attempt = maxBackoffShift
+	}
+	delay := p.Delay
+	// stops doubling before it overflows
+	for i := 0; i < attempt && delay <= math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
+
+// sleep waits for d or until ctx is cancelled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
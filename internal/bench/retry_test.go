@@ -0,0 +1,35 @@
+package bench
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackoffStaysInRange(t *testing.T) {
+	policy := RetryPolicy{Delay: 10 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, max := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond} {
+		if delay := policy.backoff(attempt); delay < max/2 || delay >= max {
+			t.Fatalf("attempt %d: delay %s, want [%s, %s)", attempt, delay, max/2, max)
+		}
+	}
+	if delay := policy.backoff(20); delay < time.Second/2 || delay >= time.Second {
+		t.Fatalf("delay %s past the max delay", delay)
+	}
+}
+
+func TestBackoffWithoutMaxDelayKeepsTheLastDelay(t *testing.T) {
+	policy := RetryPolicy{Delay: time.Millisecond}
+	last := time.Millisecond << maxBackoffShift
+	for _, attempt := range []int{maxBackoffShift, 31, 32, 64, 1000, math.MaxInt32} {
+		if delay := policy.backoff(attempt); delay < last/2 || delay >= last {
+			t.Fatalf("attempt %d: delay %s, want [%s, %s)", attempt, delay, last/2, last)
+		}
+	}
+
+	// the doubling stops before the delay overflows
+	policy = RetryPolicy{Delay: time.Duration(math.MaxInt64 / 4)}
+	if delay := policy.backoff(100); delay < policy.Delay {
+		t.Fatalf("delay %s overflowed", delay)
+	}
+}
@@ -0,0 +1,258 @@
+package bench
+
+import (
+	"context"
+	"log"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/internal/fixture"
+	"postgres_performance_test/migration"
+)
+
+// Backend is a database under test together with its built-in scenarios.
+type Backend interface {
+	Name() string
+	// Setup connects to the database and prepares the schema, errs is the
+	// error tracker the scenarios of the run report to
+	Setup(ctx context.Context, options Options, errs *Errors) error
+	// Scenarios are the built-in scenarios in run order
+	Scenarios() []Scenario
+	// Teardown removes the data of the run and disconnects, it is called even
+	// if the run was interrupted
+	Teardown()
+	Classify(err error) ErrorClass
+	Retryable(err error) bool
+}
+
+// Observer is implemented by backends that collect server-side telemetry
+// around every measured scenario.
+type Observer interface {
+	BeforeScenario(ctx context.Context, name string)
+	AfterScenario(ctx context.Context, result *Result)
+}
+
+// Scenario is a workload run against a backend, Run returns the number of
+// rows it processed.
+type Scenario struct {
+	Name string
+	Run  func(ctx context.Context, errs *Errors) int64
+	// Op is a single repeatable operation of the scenario, e.g. one insert, it
+	// is what the testing.B helpers run b.N times. It is nil for scenarios that
+	// can not be repeated, like the DDL ones
+	Op func(ctx context.Context, errs *Errors, position int) error
+	// Check marks steps that verify or inspect the data between the scenarios,
+	// they are neither measured nor counted by Options.Skip
+	Check bool
+	// Unsupported is the reason the backend can not run the scenario, it is
+	// reported as skipped instead of being run
+	Unsupported string
+	// Note is shown next to the result, e.g. when the backend runs a
+	// substitute of the scenario
+	Note string
+	// Storage measures the footprint of the rows the scenario wrote, it is
+	// called after the scenario and is not timed
+	Storage func(ctx context.Context) (TableSize, error)
+}
+
+// Options of a run.
+type Options struct {
+	// Rows are the rows of every table if no Profile is set
+	Rows    int
+	Workers int
+	// Skip is the number of leading scenarios that are not run
+	Skip int
+	// Profile is the size and the shape of the dataset, CustomProfile(Rows)
+	// if it is not set
+	Profile Profile
+	// Verify checks the integrity and checksums of the loaded data
+	Verify      bool
+	ErrorPolicy ErrorPolicy
+	// PayloadSizes are the text sizes of the payload sweep, the sweep is left
+	// out if it is empty
+	PayloadSizes []int
+	// Schema is the variant of the tables, the postgres backend creates it,
+	// sqlite only the varchar(n) variants and mysql only the default one. The
+	// backends without a schema ignore it
+	Schema migration.Schema
+	// RunID names the schema or database of the run, NewRunID() if it is empty
+	RunID string
+	// Cleanup says when the backends keeping the runs apart remove the data
+	// of the run, the others always do
+	Cleanup Cleanup
+	// Rate limits the operations of the worker pools of a scenario to this
+	// many per second together, 0 - unlimited
+	Rate float64
+	// Fixtures are the files the tables are imported from instead of
+	// generating the rows, the backend must be an Importer
+	Fixtures fixture.Sources
+	// Data generates the values of the inserted rows and the keys of the
+	// operations from its seed, the default generators with seed 0 are used
+	// if it is nil
+	Data *datagen.Generator
+}
+
+// Generator is the data generator of the run, it cuts the texts to the
+// varchar columns of the schema variant
+func (o Options) Generator() *datagen.Generator {
+	data := o.Data
+	if data == nil {
+		data = datagen.Default()
+	}
+	if o.Schema.Varchar > 0 {
+		data = data.MaxLength(o.Schema.Varchar)
+	}
+	return data
+}
+
+// Dataset is the profile of the run, its references depend on the seed of
+// the generator
+func (o Options) Dataset() Profile {
+	profile := o.Profile
+	if profile.Users == 0 {
+		profile = CustomProfile(o.Rows)
+	}
+	return profile.WithData(o.Generator())
+}
+
+// Runner runs scenarios against a backend.
+type Runner struct {
+	backend   Backend
+	options   Options
+	scenarios []Scenario
+}
+
+// NewRunner creates a runner of scenarios, the built-in scenarios of the
+// backend are run if none are given.
+func NewRunner(backend Backend, options Options, scenarios ...Scenario) *Runner {
+	return &Runner{backend: backend, options: options, scenarios: scenarios}
+}
+
+// Run sets the backend up, runs the scenarios until they are done, ctx is
+// cancelled or the error policy aborts the run, and tears the backend down.
+func (r *Runner) Run(ctx context.Context) *Report {
+	options := r.options
+	if options.RunID == "" {
+		options.RunID = NewRunID()
+	}
+	report := NewReport(r.backend.Name())
+	report.RunID = options.RunID
+	report.Seed = options.Generator().Seed()
+	report.Dataset = options.Dataset().String()
+	if !options.Schema.IsDefault() {
+		report.Schema = options.Schema.String()
+	}
+	if len(options.Fixtures) > 0 {
+		report.Dataset = "imported from " + options.Fixtures.String()
+	}
+
+	ctx, cancel := context.WithCancel(withLimiter(ctx, NewLimiter(options.Rate)))
+	defer cancel()
+	errs := NewErrors(options.ErrorPolicy, r.backend.Classify, r.backend.Retryable, cancel)
+
+	defer r.teardown(report)
+	if err := r.backend.Setup(ctx, options, errs); err != nil {
+		report.Abort(err)
+		log.Printf("Setup of %s failed: %v", r.backend.Name(), err)
+		return report
+	}
+
+	scenarios := r.scenarios
+	if len(scenarios) == 0 {
+		scenarios = r.backend.Scenarios()
+	}
+	if len(options.Fixtures) > 0 {
+		var err error
+		if scenarios, err = importing(r.backend, scenarios, options.Fixtures); err != nil {
+			report.Abort(err)
+			log.Printf("Import into %s failed: %v", r.backend.Name(), err)
+			return report
+		}
+	}
+
+	observer, _ := r.backend.(Observer)
+	skip := options.Skip
+	for _, scenario := range scenarios {
+		if ctx.Err() != nil {
+			break
+		}
+		if scenario.Check {
+			if scenario.Unsupported != "" {
+				log.Printf("Skipping %s: %s", scenario.Name, scenario.Unsupported)
+			} else {
+				scenario.Run(ctx, errs)
+			}
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if scenario.Unsupported != "" {
+			log.Printf("Skipping %s: %s", scenario.Name, scenario.Unsupported)
+			report.Add(Result{Name: scenario.Name, Skipped: true, Note: scenario.Unsupported})
+			continue
+		}
+
+		if observer != nil {
+			observer.BeforeScenario(ctx, scenario.Name)
+		}
+		result := Measure(ctx, scenario.Name, func(ctx context.Context) int64 {
+			return scenario.Run(ctx, errs)
+		})
+		result.OpStats = errs.Take()
+		result.Note = scenario.Note
+		if observer != nil {
+			observer.AfterScenario(ctx, &result)
+		}
+		if scenario.Storage != nil && ctx.Err() == nil {
+			storage, err := scenario.Storage(ctx)
+			if err != nil {
+				log.Printf("Storage of %s failed: %v", scenario.Name, err)
+			} else {
+				result.Storage = &storage
+			}
+		}
+		report.Add(result)
+	}
+
+	if err := errs.Err(); err != nil {
+		report.Abort(err)
+		log.Print("Run aborted, cleaning up...")
+	} else if ctx.Err() != nil {
+		report.MarkPartial()
+		log.Print("Run interrupted, cleaning up...")
+	}
+
+	return report
+}
+
+// teardown removes the data of the run unless the cleanup keeps it
+func (r *Runner) teardown(report *Report) {
+	report.mx.Lock()
+	failed := report.Partial || report.Err != nil
+	report.mx.Unlock()
+
+	if keeper, ok := r.backend.(Keeper); ok && r.options.Cleanup.keeps(failed) {
+		keeper.Keep()
+		log.Printf("Keeping the data of run %s", report.RunID)
+	}
+	r.backend.Teardown()
+}
+
+// Unsupported marks scenario as one the backend can not run for reason.
+func Unsupported(scenario Scenario, reason string) Scenario {
+	scenario.Unsupported = reason
+	scenario.Op = nil
+	return scenario
+}
+
+// Check wraps a step that verifies or inspects the data into a Scenario.
+func Check(name string, check func(ctx context.Context, errs *Errors)) Scenario {
+	return Scenario{
+		Name:  name,
+		Check: true,
+		Run: func(ctx context.Context, errs *Errors) int64 {
+			check(ctx, errs)
+			return 0
+		},
+	}
+}
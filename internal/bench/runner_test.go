@@ -0,0 +1,226 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"postgres_performance_test/internal/datagen"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedBackend runs a fixed list of scenarios and records what happened
+type scriptedBackend struct {
+	scenarios []Scenario
+	setupErr  error
+	ran       []string
+	observed  []string
+	tornDown  bool
+}
+
+func (s *scriptedBackend) Name() string { return "SCRIPTED" }
+
+func (s *scriptedBackend) Setup(ctx context.Context, options Options, errs *Errors) error {
+	return s.setupErr
+}
+
+func (s *scriptedBackend) Scenarios() []Scenario { return s.scenarios }
+
+func (s *scriptedBackend) Teardown() { s.tornDown = true }
+
+func (s *scriptedBackend) Classify(err error) ErrorClass { return OtherError }
+
+func (s *scriptedBackend) Retryable(err error) bool { return false }
+
+func (s *scriptedBackend) BeforeScenario(ctx context.Context, name string) {}
+
+func (s *scriptedBackend) AfterScenario(ctx context.Context, result *Result) {
+	s.observed = append(s.observed, result.Name)
+}
+
+func (s *scriptedBackend) scenario(name string, err error) Scenario {
+	return Scenario{Name: name, Run: func(ctx context.Context, errs *Errors) int64 {
+		s.ran = append(s.ran, name)
+		if errs.Do(ctx, func() error { return err }) != nil {
+			return 0
+		}
+		return 1
+	}}
+}
+
+func (s *scriptedBackend) check(name string) Scenario {
+	return Check(name, func(ctx context.Context, errs *Errors) {
+		s.ran = append(s.ran, name)
+	})
+}
+
+func TestRunnerSkipsScenariosButNotChecks(t *testing.T) {
+	backend := &scriptedBackend{}
+	backend.scenarios = []Scenario{
+		backend.scenario("first", nil),
+		backend.check("check"),
+		backend.scenario("second", nil),
+		backend.scenario("third", nil),
+	}
+
+	report := NewRunner(backend, Options{Skip: 2}).Run(context.Background())
+
+	if got := strings.Join(backend.ran, ","); got != "check,third" {
+		t.Fatalf("ran %s", got)
+	}
+	if len(report.Results) != 1 || report.Results[0].Name != "third" || report.Results[0].Rows != 1 || report.Partial {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if got := strings.Join(backend.observed, ","); got != "third" {
+		t.Fatalf("observed %s", got)
+	}
+	if !backend.tornDown {
+		t.Fatal("backend was not torn down")
+	}
+}
+
+func TestRunnerAbortsOnFirstError(t *testing.T) {
+	backend := &scriptedBackend{}
+	backend.scenarios = []Scenario{
+		backend.scenario("first", errFake),
+		backend.scenario("second", nil),
+	}
+
+	report := NewRunner(backend, Options{ErrorPolicy: ErrorPolicy{OnError: Abort}}).Run(context.Background())
+
+	if got := strings.Join(backend.ran, ","); got != "first" {
+		t.Fatalf("ran %s", got)
+	}
+	if !errors.Is(report.Err, errFake) || !report.Partial {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Results[0].Errors[OtherError] != 1 {
+		t.Fatalf("unexpected op stats %+v", report.Results[0].OpStats)
+	}
+}
+
+func TestRunnerCustomScenariosAndSetupFailure(t *testing.T) {
+	backend := &scriptedBackend{}
+	backend.scenarios = []Scenario{backend.scenario("built-in", nil)}
+
+	NewRunner(backend, Options{}, backend.scenario("custom", nil)).Run(context.Background())
+	if got := strings.Join(backend.ran, ","); got != "custom" {
+		t.Fatalf("ran %s", got)
+	}
+
+	backend = &scriptedBackend{setupErr: errFake}
+	backend.scenarios = []Scenario{backend.scenario("built-in", nil)}
+	report := NewRunner(backend, Options{}).Run(context.Background())
+	if len(backend.ran) != 0 || !errors.Is(report.Err, errFake) || !backend.tornDown {
+		t.Fatalf("unexpected report %+v after setup failure", report)
+	}
+}
+
+func TestRunnerReportsUnsupportedScenariosAsSkipped(t *testing.T) {
+	backend := &scriptedBackend{}
+	substitute := backend.scenario("substituted", nil)
+	substitute.Note = "runs something else"
+	backend.scenarios = []Scenario{
+		Unsupported(backend.scenario("unsupported", nil), "not available"),
+		Unsupported(backend.check("check"), "not available"),
+		substitute,
+	}
+
+	report := NewRunner(backend, Options{}).Run(context.Background())
+
+	if got := strings.Join(backend.ran, ","); got != "substituted" {
+		t.Fatalf("ran %s", got)
+	}
+	if len(report.Results) != 2 || report.Partial {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if skipped := report.Results[0]; !skipped.Skipped || skipped.Note != "not available" {
+		t.Fatalf("unexpected result %+v", skipped)
+	}
+	if ran := report.Results[1]; ran.Skipped || ran.Note != "runs something else" || ran.Rows != 1 {
+		t.Fatalf("unexpected result %+v", ran)
+	}
+}
+
+func TestRunnerRecordsSeedInResultFile(t *testing.T) {
+	data, err := datagen.New(42, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := &scriptedBackend{}
+	backend.scenarios = []Scenario{backend.scenario("first", errFake)}
+
+	report := NewRunner(backend, Options{Data: data, ErrorPolicy: ErrorPolicy{OnError: Abort}}).Run(context.Background())
+	if report.Seed != 42 {
+		t.Fatalf("report seed %d, want 42", report.Seed)
+	}
+
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Backend string
+		Seed    int64
+		Partial bool
+		Error   string
+		Results []Result
+	}
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Seed != 42 || saved.Backend != "SCRIPTED" || !saved.Partial || saved.Error != report.Err.Error() ||
+		len(saved.Results) != 1 || saved.Results[0].Errors[OtherError] != 1 {
+		t.Fatalf("unexpected result file %s", content)
+	}
+}
+
+func TestRunnerReportsWorkerMetrics(t *testing.T) {
+	backend := &scriptedBackend{}
+	backend.scenarios = []Scenario{
+		{Name: "pool", Run: func(ctx context.Context, errs *Errors) int64 {
+			return RunWorkers(ctx, 200, 4, func(position int) error {
+				time.Sleep(100 * time.Microsecond)
+				return nil
+			}).Done
+		}},
+		backend.scenario("no pool", nil),
+	}
+
+	report := NewRunner(backend, Options{}).Run(context.Background())
+	path := filepath.Join(t.TempDir(), "result.json")
+	if err := report.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved struct {
+		Results []Result
+	}
+	if err := json.Unmarshal(content, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Results) != 2 {
+		t.Fatalf("unexpected results %s", content)
+	}
+
+	metrics := saved.Results[0].Metrics
+	if metrics == nil || metrics.Workers != 4 || metrics.Done != 200 || metrics.RPS <= 0 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	if metrics.P50 < 100*time.Microsecond || metrics.P50 > metrics.P95 || metrics.P95 > metrics.P99 || metrics.P99 > metrics.Max {
+		t.Fatalf("unexpected latencies %+v", metrics)
+	}
+	if saved.Results[1].Metrics != nil {
+		t.Fatalf("a scenario without workers has metrics %+v", saved.Results[1].Metrics)
+	}
+}
@@ -0,0 +1,50 @@
+package bench
+
+import (
+	"fmt"
+	"log"
+)
+
+// TableSize is the on-disk footprint of a table (or a collection) after a scenario.
+type TableSize struct {
+	Name    string
+	Heap    int64
+	Indexes int64
+	Toast   int64
+	Total   int64
+
+	IndexSizes []IndexSize
+}
+
+type IndexSize struct {
+	Name string
+	Size int64
+}
+
+func LogStorage(title string, tables []TableSize) {
+	log.Printf("======= STORAGE %s =======", title)
+	log.Printf("%-20s %12s %12s %12s %12s", "table", "heap", "indexes", "toast", "total")
+	for _, table := range tables {
+		log.Printf("%-20s %12s %12s %12s %12s", table.Name,
+			FormatBytes(table.Heap), FormatBytes(table.Indexes), FormatBytes(table.Toast), FormatBytes(table.Total))
+	}
+	for _, table := range tables {
+		for _, index := range table.IndexSizes {
+			log.Printf("%-20s index %-30s %12s", table.Name, index.Name, FormatBytes(index.Size))
+		}
+	}
+	log.Print("==============================")
+}
+
+func FormatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,175 @@
+package bench
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"postgres_performance_test/internal/datagen"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// ExpectedRows is the number of rows per table the insert scenarios were asked to load.
+type ExpectedRows map[string]int64
+
+// Verify compares the expected row counts with the actual ones returned by count.
+func (e ExpectedRows) Verify(count func(table string) (int64, error)) error {
+	tables := make([]string, 0, len(e))
+	for table := range e {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var mismatches []string
+	for _, table := range tables {
+		actual, err := count(table)
+		if err != nil {
+			return fmt.Errorf("count rows of %s: %w", table, err)
+		}
+		if actual != e[table] {
+			mismatches = append(mismatches, fmt.Sprintf("%s has %d rows, %d requested", table, actual, e[table]))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("row count verification failed: %s", strings.Join(mismatches, ", "))
+	}
+
+	log.Printf("Row counts verified for %s", strings.Join(tables, ", "))
+	return nil
+}
+
+// Checksum is an order independent checksum of table rows: the sum of the
+// hashes of the rows. Rows inserted concurrently by the workers can then be
+// compared with the rows read back in any order.
+type Checksum struct {
+	sum  uint64
+	rows int64
+}
+
+// Add adds a row given by its fields, it is safe for concurrent use.
+func (c *Checksum) Add(fields ...interface{}) {
+	atomic.AddUint64(&c.sum, RowHash(fields...))
+	atomic.AddInt64(&c.rows, 1)
+}
+
+// Merge adds the rows of other, e.g. the rows of a transaction once it is committed
+func (c *Checksum) Merge(other *Checksum) {
+	atomic.AddUint64(&c.sum, other.Sum())
+	atomic.AddInt64(&c.rows, other.Rows())
+}
+
+func (c *Checksum) Sum() uint64 {
+	return atomic.LoadUint64(&c.sum)
+}
+
+func (c *Checksum) Rows() int64 {
+	return atomic.LoadInt64(&c.rows)
+}
+
+func (c *Checksum) String() string {
+	return fmt.Sprintf("%016x over %d rows", c.Sum(), c.Rows())
+}
+
+// RowHash is the FNV-1a hash of the fields of a row, integers of any size hash alike.
+func RowHash(fields ...interface{}) uint64 {
+	h := fnv.New64a()
+	for _, field := range fields {
+		fmt.Fprint(h, field)
+		h.Write([]byte{0x1f})
+	}
+	return h.Sum64()
+}
+
+// Checksums are the checksums of the rows the insert scenarios have loaded, per table.
+type Checksums map[string]*Checksum
+
+// Table returns the checksum of table, it must be called before the workers
+// start as the map itself is not safe for concurrent use.
+func (c Checksums) Table(table string) *Checksum {
+	checksum, ok := c[table]
+	if !ok {
+		checksum = &Checksum{}
+		c[table] = checksum
+	}
+	return checksum
+}
+
+// Verify compares the expected checksums with the ones computed by read from
+// the data actually stored.
+func (c Checksums) Verify(read func(table string) (*Checksum, error)) error {
+	tables := make([]string, 0, len(c))
+	for table := range c {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var mismatches []string
+	for _, table := range tables {
+		actual, err := read(table)
+		if err != nil {
+			return fmt.Errorf("checksum of %s: %w", table, err)
+		}
+		if actual.Sum() != c[table].Sum() || actual.Rows() != c[table].Rows() {
+			mismatches = append(mismatches, fmt.Sprintf("%s checksum is %s, expected %s", table, actual, c[table]))
+		}
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("checksum verification failed: %s", strings.Join(mismatches, ", "))
+	}
+	return nil
+}
+
+// IntegrityCheck is a query counting the rows that violate an expectation,
+// e.g. articles without an author.
+type IntegrityCheck struct {
+	Name  string
+	Count func() (int64, error)
+}
+
+// titleSamples is about the number of rows TitleCheck compares
+const titleSamples = 100
+
+// TitleCheck compares the titles of a sample of the rows generated at the
+// positions [0, rows) of table with the ones of the generator, read returns
+// the stored title of the row at position. A missing row or another title is
+// a violation.
+func TitleCheck(table string, rows int, data *datagen.Generator, read func(position int) (title string, found bool, err error)) IntegrityCheck {
+	return IntegrityCheck{Name: table + " with other titles than generated", Count: func() (int64, error) {
+		step := rows / titleSamples
+		if step < 1 {
+			step = 1
+		}
+
+		var violations int64
+		for position := 0; position < rows; position += step {
+			title, found, err := read(position)
+			if err != nil {
+				return 0, err
+			}
+			if !found || title != data.Text(table+".title", position) {
+				violations++
+			}
+		}
+		return violations, nil
+	}}
+}
+
+// RunIntegrityChecks runs every check and fails if any of them finds violating rows.
+func RunIntegrityChecks(checks []IntegrityCheck) error {
+	var failures []string
+	for _, check := range checks {
+		violations, err := check.Count()
+		if err != nil {
+			return fmt.Errorf("%s: %w", check.Name, err)
+		}
+		if violations != 0 {
+			failures = append(failures, fmt.Sprintf("%s: %d rows", check.Name, violations))
+		}
+		log.Printf("%-55s %d violations", check.Name, violations)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("integrity verification failed: %s", strings.Join(failures, ", "))
+	}
+	return nil
+}
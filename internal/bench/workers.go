@@ -0,0 +1,90 @@
+package bench
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Range is the half-open range [From, To) of row positions handled by one worker.
+type Range struct {
+	From int
+	To   int
+}
+
+// Partition splits [0, total) into contiguous ranges, one per worker. The first
+// total%workers ranges get one row more, so no row is lost to the remainder.
+func Partition(total, workers int) []Range {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ranges := make([]Range, workers)
+	size, rest := total/workers, total%workers
+	from := 0
+	for i := range ranges {
+		to := from + size
+		if i < rest {
+			to++
+		}
+		ranges[i] = Range{From: from, To: to}
+		from = to
+	}
+	return ranges
+}
+
+// RunWorkers calls op for every position of [0, total), split between workers
+// goroutines by Partition, until ctx is cancelled.
+func RunWorkers(ctx context.Context, total, workers int, op func(position int) error) Metrics {
+	return run(ctx, Partition(total, workers), op)
+}
+
+// RunLoop makes every one of workers goroutines call op perWorker times, until
+// ctx is cancelled. op gets the position of the call, worker w makes the calls
+// [w*perWorker, (w+1)*perWorker) in order, so ops that derive their keys from
+// the position repeat the same sequence per worker in every run.
+func RunLoop(ctx context.Context, workers, perWorker int, op func(position int) error) Metrics {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ranges := make([]Range, workers)
+	for i := range ranges {
+		ranges[i] = Range{From: i * perWorker, To: (i + 1) * perWorker}
+	}
+	return run(ctx, ranges, op)
+}
+
+// run starts a worker per range, every worker records into its own Collector
+// and the collectors are merged after all workers are done. The workers wait
+// for the Limiter of ctx before every operation, the wait is not part of the
+// latency.
+func run(ctx context.Context, ranges []Range, op func(position int) error) Metrics {
+	limit := limiter(ctx)
+	var wg sync.WaitGroup
+	collectors := make([]*Collector, len(ranges))
+
+	for i, r := range ranges {
+		collector := &Collector{}
+		collectors[i] = collector
+
+		wg.Add(1)
+		go func(r Range) {
+			defer wg.Done()
+			start := time.Now()
+			for position := r.From; position < r.To && ctx.Err() == nil; position++ {
+				if limit.Wait(ctx) != nil {
+					break
+				}
+				opStart := time.Now()
+				err := op(position)
+				collector.Observe(time.Since(opStart), err)
+			}
+			collector.Elapsed = time.Since(start)
+		}(r)
+	}
+
+	wg.Wait()
+	record(ctx, collectors)
+	return Merge(collectors)
+}
@@ -0,0 +1,209 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBackend stores rows in memory, it fails the positions listed in fail
+type fakeBackend struct {
+	mx   sync.Mutex
+	rows map[int]string
+	fail map[int]bool
+}
+
+func newFakeBackend(fail ...int) *fakeBackend {
+	backend := &fakeBackend{rows: map[int]string{}, fail: map[int]bool{}}
+	for _, position := range fail {
+		backend.fail[position] = true
+	}
+	return backend
+}
+
+func (f *fakeBackend) insert(position int, value string) error {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if f.fail[position] {
+		return fmt.Errorf("insert %d: %w", position, errFake)
+	}
+	if _, ok := f.rows[position]; ok {
+		return fmt.Errorf("insert %d: duplicate key", position)
+	}
+	f.rows[position] = value
+	return nil
+}
+
+func (f *fakeBackend) get(position int) (string, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	value, ok := f.rows[position]
+	if !ok {
+		return "", fmt.Errorf("get %d: %w", position, errFake)
+	}
+	return value, nil
+}
+
+var errFake = errors.New("fake backend error")
+
+func testErrors(policy ErrorPolicy) *Errors {
+	return NewErrors(policy, func(error) ErrorClass { return OtherError }, func(error) bool { return false }, func() {})
+}
+
+func TestPartition(t *testing.T) {
+	for _, tc := range []struct{ total, workers int }{{10, 3}, {3, 10}, {0, 4}, {1000, 7}, {5, 0}} {
+		ranges := Partition(tc.total, tc.workers)
+		next := 0
+		for _, r := range ranges {
+			if r.From != next {
+				t.Fatalf("Partition(%d, %d): range %v does not start at %d", tc.total, tc.workers, r, next)
+			}
+			if size := r.To - r.From; size < tc.total/len(ranges) || size > tc.total/len(ranges)+1 {
+				t.Fatalf("Partition(%d, %d): range %v is unbalanced", tc.total, tc.workers, r)
+			}
+			next = r.To
+		}
+		if next != tc.total {
+			t.Fatalf("Partition(%d, %d) covers [0, %d)", tc.total, tc.workers, next)
+		}
+	}
+}
+
+func TestRunWorkersInsertsEveryPositionOnce(t *testing.T) {
+	backend := newFakeBackend(3, 500, 999)
+	errs := testErrors(ErrorPolicy{OnError: Continue})
+	checksum := &Checksum{}
+
+	metrics := RunWorkers(context.Background(), 1000, 8, func(position int) error {
+		value := fmt.Sprint("name_", position)
+		err := errs.Do(context.Background(), func() error {
+			return backend.insert(position, value)
+		})
+		if err != nil {
+			return err
+		}
+		checksum.Add(position, value)
+		return nil
+	})
+
+	if metrics.Workers != 8 || metrics.Done != 997 || metrics.Failed != 3 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	if len(backend.rows) != 997 {
+		t.Fatalf("backend holds %d rows, expected 997", len(backend.rows))
+	}
+
+	expected := &Checksum{}
+	for position, value := range backend.rows {
+		expected.Add(position, value)
+	}
+	if checksum.Sum() != expected.Sum() || checksum.Rows() != expected.Rows() {
+		t.Fatalf("checksum %s, expected %s", checksum, expected)
+	}
+
+	stats := errs.Take()
+	if stats.Ops != 1000 || stats.Errors[OtherError] != 3 {
+		t.Fatalf("unexpected op stats %+v", stats)
+	}
+}
+
+func TestRunLoopMergesWorkerMetrics(t *testing.T) {
+	backend := newFakeBackend()
+	for position := 0; position < 100; position++ {
+		_ = backend.insert(position, "value")
+	}
+
+	metrics := RunLoop(context.Background(), 4, 250, func(position int) error {
+		worker := position / 250
+		if worker < 0 || worker >= 4 {
+			t.Errorf("unexpected worker %d", worker)
+		}
+		_, err := backend.get(worker * 30)
+		return err
+	})
+
+	// worker 3 reads position 90, every worker finds its row
+	if metrics.Done != 1000 || metrics.Failed != 0 {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	if metrics.RPS <= 0 || metrics.P50 > metrics.P95 || metrics.P95 > metrics.P99 || metrics.P99 > metrics.Max {
+		t.Fatalf("inconsistent metrics %+v", metrics)
+	}
+}
+
+func TestRunWorkersStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	backend := newFakeBackend()
+
+	metrics := RunWorkers(ctx, 100000, 4, func(position int) error {
+		if position == 10 {
+			cancel()
+		}
+		return backend.insert(position, "value")
+	})
+
+	if metrics.Done == 100000 {
+		t.Fatal("workers did not stop after cancellation")
+	}
+	if int(metrics.Done) != len(backend.rows) {
+		t.Fatalf("%d successful inserts, backend holds %d rows", metrics.Done, len(backend.rows))
+	}
+}
+
+func TestErrorsBudgetUnderConcurrency(t *testing.T) {
+	aborted := make(chan struct{})
+	var once sync.Once
+	errs := NewErrors(ErrorPolicy{OnError: Continue, MaxErrors: 10}, func(error) ErrorClass { return OtherError },
+		func(error) bool { return false }, func() { once.Do(func() { close(aborted) }) })
+
+	RunWorkers(context.Background(), 1000, 16, func(position int) error {
+		return errs.Do(context.Background(), func() error {
+			if position%10 == 0 {
+				return errFake
+			}
+			return nil
+		})
+	})
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("run was not aborted after the error budget was exceeded")
+	}
+	if errs.Err() == nil {
+		t.Fatal("expected the budget error")
+	}
+	if stats := errs.Take(); stats.Errors[OtherError] != 100 {
+		t.Fatalf("counted %d errors, expected 100", stats.Errors[OtherError])
+	}
+}
+
+func TestMergePercentiles(t *testing.T) {
+	first := &Collector{Elapsed: time.Second}
+	second := &Collector{Elapsed: 2 * time.Second}
+	for i := 1; i <= 100; i++ {
+		collector := first
+		if i%2 == 0 {
+			collector = second
+		}
+		collector.Observe(time.Duration(i)*time.Millisecond, nil)
+	}
+	second.Observe(time.Hour, errFake)
+
+	metrics := Merge([]*Collector{first, second})
+	if metrics.Done != 100 || metrics.Failed != 1 {
+		t.Fatalf("unexpected counts %+v", metrics)
+	}
+	if metrics.P50 != 50*time.Millisecond || metrics.P95 != 95*time.Millisecond ||
+		metrics.P99 != 99*time.Millisecond || metrics.Max != 100*time.Millisecond {
+		t.Fatalf("unexpected percentiles %+v", metrics)
+	}
+	if metrics.RPS != 50+25 {
+		t.Fatalf("RPS %.2f, expected 75", metrics.RPS)
+	}
+}
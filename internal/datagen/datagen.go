@@ -0,0 +1,239 @@
+// Package datagen generates the synthetic rows of the benchmark tables. Every
+// value is derived from the seed, the field and the position of the row only,
+// so it can be generated again, e.g. for verification, in any order.
+package datagen
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaults are the generators of the columns of the benchmark schema, the
+// tables without references share them with their counterparts
+var defaults = map[string]Spec{
+	"users.name":        {Type: "name"},
+	"users.description": {Type: "sentence", Words: &Length{Min: 3, Max: 15}},
+	"articles.title":    {Type: "sentence", Words: &Length{Min: 3, Max: 10}},
+	"articles.text":     {Type: "text", Sentences: &Length{Distribution: "normal", Min: 1, Max: 40, Mean: 8, StdDev: 3}},
+	"comments.title":    {Type: "sentence", Words: &Length{Min: 2, Max: 8}},
+	"comments.text":     {Type: "text", Sentences: &Length{Distribution: "normal", Min: 1, Max: 20, Mean: 3, StdDev: 2}},
+}
+
+// Generator generates the values of the fields of the benchmark schema.
+type Generator struct {
+	seed   int64
+	fields map[string]Field
+	salts  map[string]uint64
+	// nullable are the names of the fields that generate NULL
+	nullable []string
+	// maxLength cuts the texts of the fields to the size of the columns, 0 - no limit
+	maxLength int
+}
+
+// Default generates the data with the default generators and seed 0.
+func Default() *Generator {
+	generator, err := New(0, nil)
+	if err != nil {
+		panic(err)
+	}
+	return generator
+}
+
+// New creates the generator, specs override the defaults per field, e.g.
+// "articles.text". The backends check with CheckNullable that the columns of
+// nullable fields accept NULL.
+func New(seed int64, specs map[string]Spec) (*Generator, error) {
+	g := &Generator{seed: seed, fields: map[string]Field{}, salts: map[string]uint64{}}
+	for name, spec := range defaults {
+		if override, ok := specs[name]; ok {
+			spec = override
+		}
+		if spec.Nullable > 0 {
+			g.nullable = append(g.nullable, name)
+		}
+
+		field, err := NewField(spec)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		g.fields[name] = field
+		g.salts[name] = salt(name)
+	}
+
+	for name := range specs {
+		if _, ok := defaults[name]; !ok {
+			return nil, fmt.Errorf("unknown field %s, expected one of %v", name, Fields())
+		}
+	}
+	sort.Strings(g.nullable)
+	return g, nil
+}
+
+// CheckNullable fails if a nullable field generates a column that is NOT
+// NULL, notNull reports it by the name of the field. Every column is NOT
+// NULL if notNull is nil.
+func (g *Generator) CheckNullable(notNull func(field string) bool) error {
+	for _, name := range g.nullable {
+		if notNull == nil || notNull(name) {
+			return fmt.Errorf("field %s is nullable but its column is NOT NULL", name)
+		}
+	}
+	return nil
+}
+
+// MaxLength is a copy of the generator that cuts the texts of the fields to
+// length characters, the size of varchar columns. The payloads are not cut.
+func (g *Generator) MaxLength(length int) *Generator {
+	limited := *g
+	limited.maxLength = length
+	return &limited
+}
+
+// Fields are the names of the configurable fields
+func Fields() []string {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Value generates field of the row at position.
+func (g *Generator) Value(field string, position int) interface{} {
+	generator, ok := g.fields[field]
+	if !ok {
+		panic(fmt.Sprintf("datagen: unknown field %s", field))
+	}
+	return generator.Generate(g.rand(field, position), position)
+}
+
+// Text generates field of the row at position as the text stored in its column.
+func (g *Generator) Text(field string, position int) string {
+	return g.limit(Text(g.Value(field, position)))
+}
+
+// Arg generates field of the row at position as an argument of a statement,
+// the text of the value or nil for NULL.
+func (g *Generator) Arg(field string, position int) interface{} {
+	value := g.Value(field, position)
+	if value == nil {
+		return nil
+	}
+	return g.limit(Text(value))
+}
+
+// limit cuts text to maxLength characters
+func (g *Generator) limit(text string) string {
+	// a text has at least as many bytes as characters
+	if g.maxLength <= 0 || len(text) <= g.maxLength {
+		return text
+	}
+	characters := 0
+	for i := range text {
+		if characters == g.maxLength {
+			return text[:i]
+		}
+		characters++
+	}
+	return text
+}
+
+func (g *Generator) rand(field string, position int) *rand.Rand {
+	return rand.New(g.source(g.salts[field], position))
+}
+
+func (g *Generator) source(salt uint64, position int) *source {
+	return &source{state: uint64(g.seed) ^ salt ^ uint64(position)*0x9e3779b97f4a7c15}
+}
+
+// Seed is the seed all values and keys are derived from
+func (g *Generator) Seed() int64 {
+	return g.seed
+}
+
+// Intn picks a number in [0, n) for the call at position of stream, e.g. the
+// id a scenario selects. Like the values it depends on the seed, the stream
+// and the position only, so workers that make the same calls pick the same
+// numbers in every run with the same seed.
+func (g *Generator) Intn(stream string, position, n int) int {
+	if n <= 0 {
+		panic("datagen: invalid argument to Intn")
+	}
+	return int(g.source(salt(stream), position).Uint64() % uint64(n))
+}
+
+// Rand is the random generator of the call at position of stream, like Intn
+// it depends on the seed, the stream and the position only, e.g. for the
+// latencies and faults of a fake operation.
+func (g *Generator) Rand(stream string, position int) *rand.Rand {
+	return rand.New(g.source(salt(stream), position))
+}
+
+// Float64 picks a number in [0, 1) for the call at position of stream, like
+// Intn it depends on the seed, the stream and the position only.
+func (g *Generator) Float64(stream string, position int) float64 {
+	return float64(g.source(salt(stream), position).Uint64()>>11) / (1 << 53)
+}
+
+// Payload generates the text of exactly size bytes of the row at position,
+// made of the words of the vocabulary so it compresses like text does.
+func (g *Generator) Payload(size, position int) string {
+	r := rand.New(g.source(salt("payload"), position))
+
+	var b strings.Builder
+	b.Grow(size + 16)
+	for b.Len() < size {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(vocabulary[r.Intn(len(vocabulary))])
+	}
+	return b.String()[:size]
+}
+
+// Text renders a generated value as text, timestamps in RFC 3339.
+func Text(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+func salt(name string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return h.Sum64()
+}
+
+// source is a splitmix64 generator, it is much cheaper to seed than the
+// source of math/rand, which matters as every value gets its own
+type source struct {
+	state uint64
+}
+
+func (s *source) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (s *source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+func (s *source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
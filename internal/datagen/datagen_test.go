@@ -0,0 +1,280 @@
+package datagen
+
+import (
+	"encoding/json"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValuesDependOnSeedFieldAndPositionOnly(t *testing.T) {
+	a, err := New(42, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := New(42, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// generate b in reverse order, the values must not depend on it
+	for position := 99; position >= 0; position-- {
+		b.Text("articles.text", position)
+	}
+	for position := 0; position < 100; position++ {
+		for _, field := range Fields() {
+			if a.Text(field, position) != b.Text(field, position) {
+				t.Fatalf("%s at %d differs between generators with the same seed", field, position)
+			}
+		}
+	}
+
+	other, err := New(43, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Text("articles.text", 1) == other.Text("articles.text", 1) {
+		t.Fatal("the seed does not change the values")
+	}
+	if a.Text("articles.title", 1) == a.Text("articles.title", 2) {
+		t.Fatal("consecutive rows got the same value")
+	}
+}
+
+func TestIntnIsReproduciblePerStreamAndPosition(t *testing.T) {
+	a, b := Default(), Default()
+	counts := make([]int, 10)
+	for position := 0; position < 10000; position++ {
+		n := a.Intn("select users by id", position, 10)
+		if n != b.Intn("select users by id", position, 10) {
+			t.Fatalf("position %d differs between generators with the same seed", position)
+		}
+		counts[n]++
+	}
+	for n, count := range counts {
+		if count < 850 || count > 1150 {
+			t.Fatalf("%d picked %d times out of 10000", n, count)
+		}
+	}
+
+	same := 0
+	for position := 0; position < 100; position++ {
+		if a.Intn("select users by id", position, 1000) == a.Intn("select with filters", position, 1000) {
+			same++
+		}
+	}
+	if same > 5 {
+		t.Fatalf("streams pick the same numbers %d times out of 100", same)
+	}
+}
+
+func TestRandIsReproduciblePerStreamAndPosition(t *testing.T) {
+	a, b := Default(), Default()
+	if a.Rand("insert users/0", 7).Int63() != b.Rand("insert users/0", 7).Int63() {
+		t.Fatal("the same call differs between generators with the same seed")
+	}
+	if a.Rand("insert users/0", 7).Int63() == a.Rand("insert users/1", 7).Int63() {
+		t.Fatal("different streams generate the same numbers")
+	}
+}
+
+func TestPayloadHasExactSize(t *testing.T) {
+	g := Default()
+	for _, size := range []int{100, 2048, 1 << 20} {
+		payload := g.Payload(size, 3)
+		if len(payload) != size {
+			t.Fatalf("payload of %d bytes has %d", size, len(payload))
+		}
+		if payload != g.Payload(size, 3) || payload == g.Payload(size, 4) {
+			t.Fatalf("payload of %d bytes is not derived from the position", size)
+		}
+	}
+}
+
+func TestDefaultsAreNotEmpty(t *testing.T) {
+	g := Default()
+	for _, field := range Fields() {
+		for position := 0; position < 1000; position++ {
+			if strings.TrimSpace(g.Text(field, position)) == "" {
+				t.Fatalf("%s at %d is empty", field, position)
+			}
+		}
+	}
+}
+
+func TestUnknownAndNullableFields(t *testing.T) {
+	if _, err := New(0, map[string]Spec{"articles.body": {Type: "text"}}); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+	g, err := New(0, map[string]Spec{"articles.text": {Type: "text", Nullable: 0.1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.CheckNullable(nil); err == nil {
+		t.Fatal("expected an error for a nullable field of a NOT NULL column")
+	}
+	if err := g.CheckNullable(func(field string) bool { return field != "articles.text" }); err != nil {
+		t.Fatalf("the column of the nullable field is nullable: %v", err)
+	}
+	if err := Default().CheckNullable(nil); err != nil {
+		t.Fatalf("the defaults are not nullable: %v", err)
+	}
+	if _, err := New(0, map[string]Spec{"articles.text": {Type: "paragraph"}}); err == nil {
+		t.Fatal("expected an error for an unknown type")
+	}
+}
+
+func TestOverrideReplacesDefault(t *testing.T) {
+	g, err := New(0, map[string]Spec{"users.name": {Type: "sequence", Prefix: "name_"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := g.Text("users.name", 17); got != "name_17" {
+		t.Fatalf("users.name = %q, want name_17", got)
+	}
+}
+
+func TestMaxLengthCutsTheTexts(t *testing.T) {
+	g, err := New(0, map[string]Spec{"users.name": {Type: "constant", Value: "Zoë Müller"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited := g.MaxLength(3)
+	if got := limited.Text("users.name", 0); got != "Zoë" {
+		t.Fatalf("users.name = %q, want the first 3 characters", got)
+	}
+
+	limited = g.MaxLength(40)
+	cuts := 0
+	for position := 0; position < 100; position++ {
+		text := g.Text("articles.text", position)
+		cut := limited.Text("articles.text", position)
+		if len(cut) > 40 || !strings.HasPrefix(text, cut) || len(text) <= 40 && cut != text {
+			t.Fatalf("articles.text %q cut to %q", text, cut)
+		}
+		if arg := limited.Arg("articles.text", position); arg != cut {
+			t.Fatalf("the argument %q is not the cut text %q", arg, cut)
+		}
+		if cut != text {
+			cuts++
+		}
+	}
+	// the texts of the original generator stay longer
+	if cuts == 0 {
+		t.Fatal("no text was cut")
+	}
+}
+
+func sample(t *testing.T, spec Spec, n int) []interface{} {
+	t.Helper()
+	field, err := NewField(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(&source{state: 1})
+	values := make([]interface{}, n)
+	for i := range values {
+		values[i] = field.Generate(r, i)
+	}
+	return values
+}
+
+func TestRanges(t *testing.T) {
+	for _, v := range sample(t, Spec{Type: "int", Min: -5, Max: 5}, 1000) {
+		if n := v.(int64); n < -5 || n > 5 {
+			t.Fatalf("int %d out of range", n)
+		}
+	}
+
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	for _, v := range sample(t, Spec{Type: "timestamp", From: from, To: to}, 1000) {
+		if ts := v.(time.Time); ts.Before(from) || !ts.Before(to) {
+			t.Fatalf("timestamp %s out of range", ts)
+		}
+	}
+
+	for _, v := range sample(t, Spec{Type: "words", Words: &Length{Min: 2, Max: 4}, WordLength: &Length{Distribution: "fixed", Min: 3}}, 1000) {
+		words := strings.Fields(v.(string))
+		if len(words) < 2 || len(words) > 4 {
+			t.Fatalf("%d words out of range", len(words))
+		}
+		for _, word := range words {
+			if len(word) != 3 {
+				t.Fatalf("word %q is not 3 letters long", word)
+			}
+		}
+	}
+
+	for _, v := range sample(t, Spec{Type: "text", Sentences: &Length{Distribution: "normal", Min: 1, Max: 3, Mean: 10, StdDev: 5}}, 1000) {
+		if n := strings.Count(v.(string), "."); n < 1 || n > 3 {
+			t.Fatalf("%d sentences out of range", n)
+		}
+	}
+}
+
+func TestEnumWeights(t *testing.T) {
+	counts := map[string]int{}
+	for _, v := range sample(t, Spec{Type: "enum", Values: []string{"draft", "published"}, Weights: []float64{1, 3}}, 10000) {
+		counts[v.(string)]++
+	}
+	if share := float64(counts["published"]) / 10000; share < 0.72 || share > 0.78 {
+		t.Fatalf("published share %.3f, want about 0.75", share)
+	}
+
+	if _, err := NewField(Spec{Type: "enum", Values: []string{"a"}, Weights: []float64{1, 2}}); err == nil {
+		t.Fatal("expected an error for mismatched weights")
+	}
+}
+
+func TestNullable(t *testing.T) {
+	nulls := 0
+	for _, v := range sample(t, Spec{Type: "constant", Value: "x", Nullable: 0.2}, 10000) {
+		if v == nil {
+			nulls++
+		}
+	}
+	if share := float64(nulls) / 10000; share < 0.17 || share > 0.23 {
+		t.Fatalf("null share %.3f, want about 0.2", share)
+	}
+
+	g, err := New(0, map[string]Spec{"users.description": {Type: "constant", Value: "x", Nullable: 0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for position := 0; position < 100; position++ {
+		if arg := g.Arg("users.description", position); arg != nil && arg != "x" {
+			t.Fatalf("unexpected argument %v", arg)
+		} else if (arg == nil) != (g.Value("users.description", position) == nil) {
+			t.Fatalf("position %d: argument %v for value %v", position, arg, g.Value("users.description", position))
+		}
+	}
+}
+
+func TestUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for _, v := range sample(t, Spec{Type: "uuid"}, 100) {
+		if !pattern.MatchString(v.(string)) {
+			t.Fatalf("%q is not a version 4 uuid", v)
+		}
+	}
+}
+
+func TestSpecFromJSON(t *testing.T) {
+	var specs map[string]Spec
+	err := json.Unmarshal([]byte(`{
+		"articles.text": {"type": "text", "sentences": {"distribution": "normal", "min": 1, "max": 50, "mean": 20, "stddev": 10}},
+		"users.description": {"type": "enum", "values": ["a", "b"], "weights": [1, 1]}
+	}`), &specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(7, specs); err != nil {
+		t.Fatal(err)
+	}
+	if specs["articles.text"].Sentences.Max != 50 {
+		t.Fatalf("unexpected spec %+v", specs["articles.text"])
+	}
+}
@@ -0,0 +1,332 @@
+package datagen
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field generates the values of a column, position is the position of the row.
+type Field interface {
+	Generate(r *rand.Rand, position int) interface{}
+}
+
+// Spec configures the generator of a field in the benchmark config, e.g.
+//
+//	"articles.text": {"type": "text", "sentences": {"min": 3, "max": 12}}
+type Spec struct {
+	// Type is words, sentence, text, name, email, timestamp, int, enum, uuid,
+	// sequence or constant
+	Type string `json:"type"`
+	// Words is the number of words of words and sentence and of the sentences of text
+	Words *Length `json:"words,omitempty"`
+	// Sentences is the number of sentences of text
+	Sentences *Length `json:"sentences,omitempty"`
+	// WordLength makes words of random letters instead of picking them from a vocabulary
+	WordLength *Length `json:"word_length,omitempty"`
+	// Min and Max are the range of int
+	Min int64 `json:"min,omitempty"`
+	Max int64 `json:"max,omitempty"`
+	// From and To are the range of timestamp
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+	// Values are picked by enum, with the relative Weights if given
+	Values  []string  `json:"values,omitempty"`
+	Weights []float64 `json:"weights,omitempty"`
+	// Domains of email
+	Domains []string `json:"domains,omitempty"`
+	// Prefix of sequence, which appends the position of the row
+	Prefix string `json:"prefix,omitempty"`
+	// Value of constant
+	Value string `json:"value,omitempty"`
+	// Nullable is the probability of a NULL instead of a value
+	Nullable float64 `json:"nullable,omitempty"`
+}
+
+// Length is a distribution of lengths, uniform in [Min, Max] unless
+// Distribution is normal (around Mean, clamped to [Min, Max]) or fixed (Min).
+type Length struct {
+	Distribution string  `json:"distribution,omitempty"`
+	Min          int     `json:"min"`
+	Max          int     `json:"max,omitempty"`
+	Mean         float64 `json:"mean,omitempty"`
+	StdDev       float64 `json:"stddev,omitempty"`
+}
+
+func (l Length) validate() error {
+	switch l.Distribution {
+	case "", "uniform", "normal", "fixed":
+	default:
+		return fmt.Errorf("unknown distribution %q, expected uniform, normal or fixed", l.Distribution)
+	}
+	if l.Min < 0 || (l.Distribution != "fixed" && l.Max < l.Min) {
+		return fmt.Errorf("invalid length range [%d, %d]", l.Min, l.Max)
+	}
+	return nil
+}
+
+func (l Length) sample(r *rand.Rand) int {
+	switch l.Distribution {
+	case "fixed":
+		return l.Min
+	case "normal":
+		n := int(r.NormFloat64()*l.StdDev + l.Mean)
+		if n < l.Min {
+			return l.Min
+		}
+		if n > l.Max {
+			return l.Max
+		}
+		return n
+	}
+	return l.Min + r.Intn(l.Max-l.Min+1)
+}
+
+// NewField creates the generator configured by spec.
+func NewField(spec Spec) (Field, error) {
+	field, err := newField(spec)
+	if err != nil {
+		return nil, err
+	}
+	if spec.Nullable < 0 || spec.Nullable > 1 {
+		return nil, fmt.Errorf("nullable %v is not a probability", spec.Nullable)
+	}
+	if spec.Nullable > 0 {
+		return nullable{field: field, probability: spec.Nullable}, nil
+	}
+	return field, nil
+}
+
+func newField(spec Spec) (Field, error) {
+	for _, length := range []*Length{spec.Words, spec.Sentences, spec.WordLength} {
+		if length != nil {
+			if err := length.validate(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	wordsOf := func(fallback Length) words {
+		w := words{count: fallback, letters: spec.WordLength}
+		if spec.Words != nil {
+			w.count = *spec.Words
+		}
+		return w
+	}
+
+	switch spec.Type {
+	case "words":
+		return wordsOf(Length{Min: 1, Max: 10}), nil
+	case "sentence":
+		return sentences{count: Length{Distribution: "fixed", Min: 1}, words: wordsOf(Length{Min: 4, Max: 16})}, nil
+	case "text":
+		count := Length{Min: 2, Max: 8}
+		if spec.Sentences != nil {
+			count = *spec.Sentences
+		}
+		return sentences{count: count, words: wordsOf(Length{Min: 4, Max: 16})}, nil
+	case "name":
+		return name{}, nil
+	case "email":
+		domains := spec.Domains
+		if len(domains) == 0 {
+			domains = []string{"example.com", "example.org", "example.net"}
+		}
+		return email{domains: domains}, nil
+	case "timestamp":
+		if !spec.To.After(spec.From) {
+			return nil, fmt.Errorf("timestamp range %s - %s is empty", spec.From, spec.To)
+		}
+		return timestamp{from: spec.From, span: spec.To.Sub(spec.From)}, nil
+	case "int":
+		if spec.Max < spec.Min {
+			return nil, fmt.Errorf("invalid int range [%d, %d]", spec.Min, spec.Max)
+		}
+		return integer{min: spec.Min, max: spec.Max}, nil
+	case "enum":
+		return newEnum(spec.Values, spec.Weights)
+	case "uuid":
+		return uuid{}, nil
+	case "sequence":
+		return sequence(spec.Prefix), nil
+	case "constant":
+		return constant(spec.Value), nil
+	}
+	return nil, fmt.Errorf("unknown field type %q", spec.Type)
+}
+
+type nullable struct {
+	field       Field
+	probability float64
+}
+
+func (n nullable) Generate(r *rand.Rand, position int) interface{} {
+	if r.Float64() < n.probability {
+		return nil
+	}
+	return n.field.Generate(r, position)
+}
+
+type words struct {
+	count   Length
+	letters *Length
+}
+
+func (w words) Generate(r *rand.Rand, _ int) interface{} {
+	var b strings.Builder
+	w.write(&b, r)
+	return b.String()
+}
+
+func (w words) write(b *strings.Builder, r *rand.Rand) {
+	for i, n := 0, w.count.sample(r); i < n; i++ {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if w.letters == nil {
+			b.WriteString(vocabulary[r.Intn(len(vocabulary))])
+			continue
+		}
+		for j, length := 0, w.letters.sample(r); j < length; j++ {
+			b.WriteByte(byte('a' + r.Intn(26)))
+		}
+	}
+}
+
+type sentences struct {
+	count Length
+	words words
+}
+
+func (s sentences) Generate(r *rand.Rand, _ int) interface{} {
+	var b strings.Builder
+	for i, n := 0, s.count.sample(r); i < n; i++ {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		start := b.Len()
+		s.words.write(&b, r)
+		if b.Len() == start {
+			continue
+		}
+		b.WriteByte('.')
+	}
+	return capitalizeSentences(b.String())
+}
+
+// capitalizeSentences upper-cases the first letter of the text and of every
+// word after a period
+func capitalizeSentences(text string) string {
+	out := []byte(text)
+	upper := true
+	for i, c := range out {
+		if upper && c >= 'a' && c <= 'z' {
+			out[i] = c - 'a' + 'A'
+		}
+		if c != ' ' {
+			upper = c == '.'
+		}
+	}
+	return string(out)
+}
+
+type name struct{}
+
+func (name) Generate(r *rand.Rand, _ int) interface{} {
+	return firstNames[r.Intn(len(firstNames))] + " " + lastNames[r.Intn(len(lastNames))]
+}
+
+type email struct {
+	domains []string
+}
+
+func (e email) Generate(r *rand.Rand, _ int) interface{} {
+	return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(firstNames[r.Intn(len(firstNames))]),
+		strings.ToLower(lastNames[r.Intn(len(lastNames))]), r.Intn(1000), e.domains[r.Intn(len(e.domains))])
+}
+
+type timestamp struct {
+	from time.Time
+	span time.Duration
+}
+
+func (t timestamp) Generate(r *rand.Rand, _ int) interface{} {
+	return t.from.Add(time.Duration(r.Int63n(int64(t.span)))).UTC().Truncate(time.Microsecond)
+}
+
+type integer struct {
+	min, max int64
+}
+
+func (i integer) Generate(r *rand.Rand, _ int) interface{} {
+	return i.min + r.Int63n(i.max-i.min+1)
+}
+
+type enum struct {
+	values     []string
+	cumulative []float64
+}
+
+func newEnum(values []string, weights []float64) (Field, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("enum has no values")
+	}
+	if len(weights) != 0 && len(weights) != len(values) {
+		return nil, fmt.Errorf("enum has %d values but %d weights", len(values), len(weights))
+	}
+
+	e := enum{values: values, cumulative: make([]float64, len(values))}
+	total := 0.0
+	for i := range values {
+		weight := 1.0
+		if len(weights) != 0 {
+			weight = weights[i]
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("enum weight %v of %q is negative", weight, values[i])
+		}
+		total += weight
+		e.cumulative[i] = total
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("enum weights are all 0")
+	}
+	for i := range e.cumulative {
+		e.cumulative[i] /= total
+	}
+	return e, nil
+}
+
+func (e enum) Generate(r *rand.Rand, _ int) interface{} {
+	draw := r.Float64()
+	for i, limit := range e.cumulative {
+		if draw < limit {
+			return e.values[i]
+		}
+	}
+	return e.values[len(e.values)-1]
+}
+
+type uuid struct{}
+
+// Generate returns a random (version 4) UUID
+func (uuid) Generate(r *rand.Rand, _ int) interface{} {
+	var b [16]byte
+	r.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+type sequence string
+
+func (s sequence) Generate(_ *rand.Rand, position int) interface{} {
+	return string(s) + strconv.Itoa(position)
+}
+
+type constant string
+
+func (c constant) Generate(*rand.Rand, int) interface{} {
+	return string(c)
+}
@@ -0,0 +1,28 @@
+package datagen
+
+// vocabulary are the words of lorem ipsum, picked at random they compress
+// roughly like natural text
+var vocabulary = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+	"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+	"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+	"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+	"velit", "esse", "cillum", "fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat",
+	"non", "proident", "sunt", "culpa", "qui", "officia", "deserunt", "mollit", "anim", "id",
+	"est", "laborum", "perspiciatis", "unde", "omnis", "iste", "natus", "error", "voluptatem", "accusantium",
+	"doloremque", "laudantium", "totam", "rem", "aperiam", "eaque", "ipsa", "quae", "ab", "illo",
+	"inventore", "veritatis", "quasi", "architecto", "beatae", "vitae", "dicta", "explicabo", "nemo", "ipsam",
+	"quia", "voluptas", "aspernatur", "aut", "odit", "fugit", "consequuntur", "magni", "dolores", "eos",
+}
+
+var firstNames = []string{
+	"Anna", "Boris", "Carla", "Daniel", "Elena", "Farid", "Grace", "Hiroshi", "Ivan", "Julia",
+	"Kwame", "Laura", "Maria", "Nikolai", "Olga", "Pedro", "Qing", "Rosa", "Sergei", "Tatiana",
+	"Umar", "Vera", "Wei", "Ximena", "Yusuf", "Zoe", "Alexei", "Bianca", "Chen", "Dmitri",
+}
+
+var lastNames = []string{
+	"Smith", "Ivanova", "Garcia", "Kim", "Nguyen", "Petrov", "Rossi", "Schmidt", "Tanaka", "Silva",
+	"Kowalski", "Novak", "Haddad", "Okafor", "Jensen", "Dubois", "Murphy", "Sokolov", "Lopez", "Chen",
+	"Andersson", "Popescu", "Yilmaz", "Costa", "Fischer", "Horvat", "Ibrahim", "Kuznetsov", "Moreau", "Wong",
+}
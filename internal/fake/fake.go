@@ -0,0 +1,353 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Config of the fake backend. Latencies and faults are derived from Seed, the
+// scenario and the position of the operation, so they do not depend on how
+// the workers are scheduled and are the same in every run with the same seed.
+type Config struct {
+	Seed    int64
+	Latency Latency
+	Faults  []Fault
+}
+
+// Fault makes operations fail with an error of Class.
+type Fault struct {
+	// Scenario limits the fault to one scenario, all scenarios if empty
+	Scenario string
+	// Positions are the positions of the operations that fail
+	Positions []int
+	// Every makes every nth position fail, e.g. 10 fails positions 9, 19, ...
+	Every int
+	// Probability makes operations fail at random
+	Probability float64
+	// Attempts makes the fault transient, only the first Attempts calls of
+	// an operation with the same position fail, 0 means they always fail.
+	Attempts  int
+	Class     bench.ErrorClass
+	Retryable bool
+}
+
+// Error is returned by the operations hit by a Fault.
+type Error struct {
+	Scenario  string
+	Position  int
+	Attempt   int
+	Class     bench.ErrorClass
+	Retryable bool
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("injected %s error in %s at position %d, attempt %d", e.Class, e.Scenario, e.Position, e.Attempt)
+}
+
+type opKey struct {
+	scenario string
+	position int
+}
+
+// Backend keeps the rows in memory and adds artificial latency and errors to
+// every operation, it runs the harness without a database.
+type Backend struct {
+	config       Config
+	noise        *datagen.Generator
+	mx           sync.Mutex
+	users        map[int]string
+	articles     map[int]int
+	columns      []string
+	attempts     map[opKey]int
+	profile      bench.Profile
+	data         *datagen.Generator
+	poolCount    int
+	expectedRows bench.ExpectedRows
+}
+
+func New(config Config) *Backend {
+	// the latencies and faults have a generator of their own, so they do not
+	// depend on the data of the run
+	noise, err := datagen.New(config.Seed, nil)
+	if err != nil {
+		panic(err)
+	}
+	return &Backend{config: config, noise: noise}
+}
+
+func (b *Backend) Name() string {
+	return "FAKE"
+}
+
+func (b *Backend) Setup(ctx context.Context, options bench.Options, errs *bench.Errors) error {
+	b.profile = options.Dataset()
+	b.data = options.Generator()
+	b.poolCount = options.Workers
+	b.expectedRows = bench.ExpectedRows{}
+
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	b.users = map[int]string{}
+	b.articles = map[int]int{}
+	b.columns = nil
+	b.attempts = map[opKey]int{}
+	return nil
+}
+
+func (b *Backend) Scenarios() []bench.Scenario {
+	return []bench.Scenario{
+		{Name: "insert users", Run: b.insertUsers, Op: b.insertUser},
+		{Name: "insert articles", Run: b.insertArticles, Op: b.insertArticle},
+		bench.Check("verify row counts", b.verifyRowCounts),
+		{Name: "select users by id", Run: b.selectFromIdUsers, Op: b.selectUserById},
+		{Name: "add nullable column", Run: b.addNullableColumn},
+	}
+}
+
+func (b *Backend) Teardown() {}
+
+func (b *Backend) Classify(err error) bench.ErrorClass {
+	var fakeErr *Error
+	if errors.As(err, &fakeErr) {
+		return fakeErr.Class
+	}
+	return bench.ClassifyTransport(err)
+}
+
+func (b *Backend) Retryable(err error) bool {
+	var fakeErr *Error
+	return errors.As(err, &fakeErr) && fakeErr.Retryable
+}
+
+// Rows is the number of rows stored in table
+func (b *Backend) Rows(table string) int {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	switch table {
+	case "users":
+		return len(b.users)
+	case "articles":
+		return len(b.articles)
+	}
+	return 0
+}
+
+// Columns are the columns added by the DDL scenarios
+func (b *Backend) Columns() []string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	return append([]string(nil), b.columns...)
+}
+
+// do runs apply as an operation of scenario through errs, every call is
+// delayed by the latency and fails if a fault hits it
+func (b *Backend) do(ctx context.Context, errs *bench.Errors, scenario string, position int, apply func(r *rand.Rand) error) error {
+	return errs.Do(ctx, func() error {
+		attempt := b.nextAttempt(scenario, position)
+		r := b.random(scenario, position, attempt)
+
+		if b.config.Latency != nil {
+			if err := sleep(ctx, b.config.Latency.Sample(r)); err != nil {
+				return err
+			}
+		}
+		if err := b.fault(scenario, position, attempt, r); err != nil {
+			return err
+		}
+		return apply(r)
+	})
+}
+
+func (b *Backend) nextAttempt(scenario string, position int) int {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	key := opKey{scenario: scenario, position: position}
+	attempt := b.attempts[key]
+	b.attempts[key]++
+	return attempt
+}
+
+// random is the generator of the latency and the faults of a single call,
+// seeded by the seed of the config and the call itself
+func (b *Backend) random(scenario string, position, attempt int) *rand.Rand {
+	return b.noise.Rand(scenario+"/"+strconv.Itoa(attempt), position)
+}
+
+func (b *Backend) fault(scenario string, position, attempt int, r *rand.Rand) error {
+	// drawn before the faults are checked, so adding a fault does not shift the other samples
+	draw := r.Float64()
+
+	for _, fault := range b.config.Faults {
+		if fault.Scenario != "" && fault.Scenario != scenario {
+			continue
+		}
+		if fault.Attempts > 0 && attempt >= fault.Attempts {
+			continue
+		}
+
+		hit := fault.Probability > 0 && draw < fault.Probability
+		hit = hit || fault.Every > 0 && (position+1)%fault.Every == 0
+		for _, p := range fault.Positions {
+			hit = hit || p == position
+		}
+		if hit {
+			return &Error{Scenario: scenario, Position: position, Attempt: attempt, Class: fault.Class, Retryable: fault.Retryable}
+		}
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// verifyRowCounts fails the run if a table does not hold the rows that were requested
+func (b *Backend) verifyRowCounts(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	err := b.expectedRows.Verify(func(table string) (int64, error) {
+		return int64(b.Rows(table)), nil
+	})
+	if err != nil {
+		errs.Fail(err)
+	}
+}
+
+func (b *Backend) insertUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ============")
+	log.Printf("Insert %d users in progress...", b.profile.Users)
+
+	b.expectedRows["users"] += int64(b.profile.Users)
+	metrics := bench.RunWorkers(ctx, b.profile.Users, b.poolCount, func(currentPosition int) error {
+		return b.insertUser(ctx, errs, currentPosition)
+	})
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, time.Since(start))
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertUser(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	return b.do(ctx, errs, "insert users", currentPosition, func(*rand.Rand) error {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		if _, ok := b.users[currentPosition]; ok {
+			return &Error{Scenario: "insert users", Position: currentPosition, Class: bench.DuplicateKey}
+		}
+		b.users[currentPosition] = fmt.Sprint("name_", currentPosition)
+		return nil
+	})
+}
+
+func (b *Backend) insertArticles(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ARTICLES ============")
+	log.Printf("Insert %d articles in progress...", b.profile.Articles())
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	metrics := bench.RunWorkers(ctx, b.profile.Articles(), b.poolCount, func(currentPosition int) error {
+		return b.insertArticle(ctx, errs, currentPosition)
+	})
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, time.Since(start))
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+// insertArticle fails with a constraint violation if the author does not exist
+func (b *Backend) insertArticle(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	return b.do(ctx, errs, "insert articles", currentPosition, func(*rand.Rand) error {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		authorId := b.profile.AuthorOf(currentPosition)
+		if _, ok := b.users[authorId]; !ok {
+			return &Error{Scenario: "insert articles", Position: currentPosition, Class: bench.ConstraintViolation}
+		}
+		if _, ok := b.articles[currentPosition]; ok {
+			return &Error{Scenario: "insert articles", Position: currentPosition, Class: bench.DuplicateKey}
+		}
+		b.articles[currentPosition] = authorId
+		return nil
+	})
+}
+
+func (b *Backend) selectFromIdUsers(ctx context.Context, errs *bench.Errors) int64 {
+	log.Print("======= SELECT FROM ID =======")
+	log.Printf("Select %d users in progress...", b.profile.Users)
+
+	var selectsPerConnection int = 1000
+
+	metrics := bench.RunLoop(ctx, b.poolCount, selectsPerConnection, func(position int) error {
+		return b.selectUserById(ctx, errs, position)
+	})
+
+	log.Printf("Average RPS for %d pools = %.0f selects", b.poolCount, metrics.RPS)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+// selectUserById picks the id from the data generator like the other
+// backends, so every worker selects the same ids in every run with the same
+// seed
+func (b *Backend) selectUserById(ctx context.Context, errs *bench.Errors, position int) error {
+	id := b.data.Intn("select users by id", position, b.profile.Users)
+	return b.do(ctx, errs, "select users by id", position, func(*rand.Rand) error {
+
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		if _, ok := b.users[id]; !ok {
+			return fmt.Errorf("user %d not found", id)
+		}
+		return nil
+	})
+}
+
+func (b *Backend) addNullableColumn(ctx context.Context, errs *bench.Errors) int64 {
+	log.Print("======= ADD NULLABLE COLUMN =======")
+
+	err := b.do(ctx, errs, "add nullable column", 0, func(*rand.Rand) error {
+		b.mx.Lock()
+		defer b.mx.Unlock()
+
+		b.columns = append(b.columns, "nullable_column")
+		return nil
+	})
+	if err != nil {
+		return 0
+	}
+
+	log.Print("==============================")
+	return 0
+}
@@ -0,0 +1,274 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/internal/fixture"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func run(t *testing.T, backend *Backend, options bench.Options) *bench.Report {
+	t.Helper()
+	if options.Rows == 0 {
+		options.Rows = 1000
+	}
+	if options.Workers == 0 {
+		options.Workers = 8
+	}
+	return bench.NewRunner(backend, options).Run(context.Background())
+}
+
+func result(t *testing.T, report *bench.Report, name string) bench.Result {
+	t.Helper()
+	for _, result := range report.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	t.Fatalf("no result for %q in %+v", name, report.Results)
+	return bench.Result{}
+}
+
+func TestRunWithoutFaults(t *testing.T) {
+	report := run(t, New(Config{Seed: 1}), bench.Options{})
+
+	if report.Err != nil || report.Partial {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for name, rows := range map[string]int64{"insert users": 1000, "insert articles": 1000, "select users by id": 8000} {
+		if got := result(t, report, name); got.Rows != rows || got.Ops != rows || len(got.Errors) != 0 {
+			t.Fatalf("%s: unexpected result %+v", name, got)
+		}
+	}
+}
+
+func TestContinueCountsFaultsPerClass(t *testing.T) {
+	backend := New(Config{Seed: 1, Faults: []Fault{
+		{Scenario: "insert users", Every: 10, Class: bench.Timeout},
+		{Scenario: "insert users", Positions: []int{0, 1}, Class: bench.DuplicateKey},
+	}})
+	report := run(t, backend, bench.Options{ErrorPolicy: bench.ErrorPolicy{OnError: bench.Continue}})
+
+	users := result(t, report, "insert users")
+	if users.Rows != 898 || users.Errors[bench.Timeout] != 100 || users.Errors[bench.DuplicateKey] != 2 {
+		t.Fatalf("unexpected result %+v", users)
+	}
+	// every user writes one article, the articles of the 102 missing authors violate the foreign key
+	if articles := result(t, report, "insert articles"); articles.Errors[bench.ConstraintViolation] != 102 {
+		t.Fatalf("unexpected result %+v", articles)
+	}
+	// and the row counts do not match the request
+	if report.Err == nil || !report.Partial {
+		t.Fatalf("row count verification did not fail the run: %+v", report)
+	}
+}
+
+func TestTransientFaultsAreRetried(t *testing.T) {
+	backend := New(Config{Seed: 1, Faults: []Fault{
+		{Scenario: "insert users", Every: 5, Attempts: 2, Class: bench.SerializationFailure, Retryable: true},
+	}})
+	report := run(t, backend, bench.Options{ErrorPolicy: bench.ErrorPolicy{
+		OnError: bench.Abort,
+		Retry:   bench.RetryPolicy{Retries: 3, Delay: time.Microsecond, MaxDelay: time.Microsecond},
+	}})
+
+	users := result(t, report, "insert users")
+	if report.Err != nil || users.Rows != 1000 || users.Retries != 400 || len(users.Errors) != 0 {
+		t.Fatalf("unexpected result %+v, err %v", users, report.Err)
+	}
+}
+
+func TestAbortStopsOnInjectedError(t *testing.T) {
+	backend := New(Config{Seed: 1, Faults: []Fault{
+		{Scenario: "insert articles", Positions: []int{500}, Class: bench.ConnectionReset},
+	}})
+	report := run(t, backend, bench.Options{ErrorPolicy: bench.ErrorPolicy{OnError: bench.Abort}})
+
+	var fakeErr *Error
+	if !errors.As(report.Err, &fakeErr) || fakeErr.Position != 500 || fakeErr.Class != bench.ConnectionReset {
+		t.Fatalf("unexpected error %v", report.Err)
+	}
+	if len(report.Results) != 2 || !report.Partial {
+		t.Fatalf("run went on after the error: %+v", report.Results)
+	}
+	if len(backend.Columns()) != 0 {
+		t.Fatal("DDL scenario ran after the error")
+	}
+}
+
+func TestSameSeedFailsSamePositions(t *testing.T) {
+	failed := func(seed int64) []int {
+		backend := New(Config{Seed: seed, Faults: []Fault{{Probability: 0.05, Class: bench.OtherError}}})
+		run(t, backend, bench.Options{ErrorPolicy: bench.ErrorPolicy{OnError: bench.Continue}})
+
+		var positions []int
+		for position := 0; position < 1000; position++ {
+			if _, ok := backend.users[position]; !ok {
+				positions = append(positions, position)
+			}
+		}
+		sort.Ints(positions)
+		return positions
+	}
+
+	first, second, other := failed(7), failed(7), failed(8)
+	if len(first) < 20 || len(first) > 80 {
+		t.Fatalf("%d of 1000 operations failed with probability 0.05", len(first))
+	}
+	if !equal(first, second) {
+		t.Fatalf("same seed failed different positions: %v and %v", first, second)
+	}
+	if equal(first, other) {
+		t.Fatal("different seeds failed the same positions")
+	}
+}
+
+func TestLatencyIsAddedToEveryOperation(t *testing.T) {
+	backend := New(Config{Seed: 1, Latency: Constant(2 * time.Millisecond)})
+	report := bench.NewRunner(backend, bench.Options{Rows: 40, Workers: 4}, backend.Scenarios()[0]).Run(context.Background())
+
+	users := result(t, report, "insert users")
+	if avg := users.FirstAttempt / time.Duration(users.Ops); avg < 2*time.Millisecond {
+		t.Fatalf("average latency %s is below the configured 2ms", avg)
+	}
+	if users.Elapsed < 20*time.Millisecond {
+		t.Fatalf("40 operations of 2ms on 4 workers took %s", users.Elapsed)
+	}
+}
+
+func TestLatencyDistributions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	samples := func(latency Latency) []time.Duration {
+		values := make([]time.Duration, 10000)
+		for i := range values {
+			values[i] = latency.Sample(r)
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		return values
+	}
+
+	if got := samples(Constant(time.Millisecond)); got[0] != time.Millisecond || got[len(got)-1] != time.Millisecond {
+		t.Fatalf("constant latency varies from %s to %s", got[0], got[len(got)-1])
+	}
+	if got := samples(Uniform(time.Millisecond, 2*time.Millisecond)); got[0] < time.Millisecond || got[len(got)-1] >= 2*time.Millisecond {
+		t.Fatalf("uniform latency out of range: %s to %s", got[0], got[len(got)-1])
+	}
+	if got := samples(Normal(time.Millisecond, time.Millisecond)); got[0] < 0 || !near(got[len(got)/2], time.Millisecond) {
+		t.Fatalf("normal latency: min %s, median %s", got[0], got[len(got)/2])
+	}
+	got := samples(LongTail(time.Millisecond, 1))
+	if p50, p99 := got[len(got)/2], got[len(got)*99/100]; !near(p50, time.Millisecond) || !near(p99, 10*time.Millisecond) {
+		t.Fatalf("long tail latency: p50 %s, p99 %s", p50, p99)
+	}
+}
+
+// near reports whether d is within 10% of want
+func near(d, want time.Duration) bool {
+	return d > want*9/10 && d < want*11/10
+}
+
+func equal(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func fixtures(t *testing.T, articles string) bench.Options {
+	t.Helper()
+	dir := t.TempDir()
+	users := "id,name,description\n"
+	for id := 0; id < 10; id++ {
+		users += fmt.Sprintf("%d,user %d,\n", id, id)
+	}
+	for name, content := range map[string]string{"users.csv": users, "articles.jsonl": articles, "comments.csv": "id,author_id,article_id,title,text\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources, err := fixture.Find(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bench.Options{Fixtures: sources}
+}
+
+func TestImportReplacesTheInserts(t *testing.T) {
+	var articles string
+	for id := 0; id < 20; id++ {
+		articles += fmt.Sprintf("{\"id\": %d, \"author_id\": %d, \"title\": \"t\", \"text\": \"x\"}\n", id, id%10)
+	}
+	backend := New(Config{Seed: 1})
+	report := run(t, backend, fixtures(t, articles))
+
+	if report.Err != nil || report.Partial {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	var names []string
+	for _, result := range report.Results {
+		names = append(names, result.Name)
+	}
+	if want := []string{"import users", "import articles", "import comments", "select users by id", "add nullable column"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("scenarios %v, want %v", names, want)
+	}
+	if users, articles := result(t, report, "import users"), result(t, report, "import articles"); users.Rows != 10 || articles.Rows != 20 {
+		t.Fatalf("imported %d users and %d articles", users.Rows, articles.Rows)
+	}
+	if comments := result(t, report, "import comments"); !comments.Skipped {
+		t.Fatalf("the fake imported comments: %+v", comments)
+	}
+	// the reads pick the ids among the imported users
+	if selects := result(t, report, "select users by id"); len(selects.Errors) != 0 {
+		t.Fatalf("unexpected result %+v", selects)
+	}
+}
+
+func TestImportOfArticleWithoutAuthorFails(t *testing.T) {
+	options := fixtures(t, "{\"id\": 1, \"author_id\": 99, \"title\": \"t\", \"text\": \"x\"}\n")
+	options.ErrorPolicy.OnError = bench.Abort
+	report := run(t, New(Config{Seed: 1}), options)
+
+	if articles := result(t, report, "import articles"); articles.Errors[bench.ConstraintViolation] != 1 {
+		t.Fatalf("unexpected result %+v", articles)
+	}
+	if report.Err == nil {
+		t.Fatal("the failed import did not abort the run")
+	}
+}
+
+func TestSelectKeysFromTheDataGenerator(t *testing.T) {
+	data, err := datagen.New(7, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend := New(Config{Seed: 1})
+	if err := backend.Setup(context.Background(), bench.Options{Rows: 100, Workers: 1, Data: data}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the users the generator picks exist, the config seed does not matter
+	for position := 0; position < 50; position++ {
+		backend.users[data.Intn("select users by id", position, 100)] = "user"
+	}
+	errs := bench.NewErrors(bench.ErrorPolicy{}, backend.Classify, backend.Retryable, func() {})
+	for position := 0; position < 50; position++ {
+		if err := backend.selectUserById(context.Background(), errs, position); err != nil {
+			t.Fatalf("position %d: %v", position, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/fixture"
+)
+
+// Import adds the users and articles of the fixture, the fake keeps no
+// comments. An article of a missing author fails with a constraint violation.
+func (b *Backend) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	if table != "users" && table != "articles" {
+		return 0, fmt.Errorf("the fake keeps no %s", table)
+	}
+
+	var imported int64
+	for ctx.Err() == nil {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, err
+		}
+		if err := b.importRow(table, row); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+
+	b.expectedRows[table] += imported
+	b.profile = b.profile.Imported(table, imported)
+	return imported, ctx.Err()
+}
+
+func (b *Backend) importRow(table string, row []interface{}) error {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+
+	id := row[0].(int)
+	if table == "users" {
+		if _, ok := b.users[id]; ok {
+			return &Error{Scenario: "import users", Position: id, Class: bench.DuplicateKey}
+		}
+		b.users[id] = row[1].(string)
+		return nil
+	}
+
+	authorId := row[1].(int)
+	if _, ok := b.users[authorId]; !ok {
+		return &Error{Scenario: "import articles", Position: id, Class: bench.ConstraintViolation}
+	}
+	if _, ok := b.articles[id]; ok {
+		return &Error{Scenario: "import articles", Position: id, Class: bench.DuplicateKey}
+	}
+	b.articles[id] = authorId
+	return nil
+}
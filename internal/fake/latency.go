@@ -0,0 +1,72 @@
+package fake
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Latency is a distribution of the artificial latency added to every operation.
+type Latency interface {
+	Sample(r *rand.Rand) time.Duration
+}
+
+type constant time.Duration
+
+// Constant delays every operation by d
+func Constant(d time.Duration) Latency {
+	return constant(d)
+}
+
+func (c constant) Sample(*rand.Rand) time.Duration {
+	return time.Duration(c)
+}
+
+type uniform struct {
+	min, max time.Duration
+}
+
+// Uniform picks the latency evenly from [min, max)
+func Uniform(min, max time.Duration) Latency {
+	return uniform{min: min, max: max}
+}
+
+func (u uniform) Sample(r *rand.Rand) time.Duration {
+	if u.max <= u.min {
+		return u.min
+	}
+	return u.min + time.Duration(r.Int63n(int64(u.max-u.min)))
+}
+
+type normal struct {
+	mean, stddev time.Duration
+}
+
+// Normal picks the latency from a normal distribution, negative samples are 0
+func Normal(mean, stddev time.Duration) Latency {
+	return normal{mean: mean, stddev: stddev}
+}
+
+func (n normal) Sample(r *rand.Rand) time.Duration {
+	d := time.Duration(r.NormFloat64()*float64(n.stddev)) + n.mean
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+type longTail struct {
+	median time.Duration
+	sigma  float64
+}
+
+// LongTail picks the latency from a log-normal distribution with the given
+// median, a larger sigma makes the slow operations slower, e.g. 1 puts p99 at
+// about ten times the median
+func LongTail(median time.Duration, sigma float64) Latency {
+	return longTail{median: median, sigma: sigma}
+}
+
+func (l longTail) Sample(r *rand.Rand) time.Duration {
+	return time.Duration(float64(l.median) * math.Exp(r.NormFloat64()*l.sigma))
+}
@@ -0,0 +1,267 @@
+// Package fixture reads the rows of the benchmark tables from CSV or JSON
+// Lines files, e.g. an anonymised dump of production data, and maps their
+// columns to the columns of the schema.
+package fixture
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// the formats of the files
+const (
+	CSV   = "csv"
+	JSONL = "jsonl"
+)
+
+// Tables are the tables that can be imported in the order they are loaded,
+// the referenced rows first
+var Tables = []string{"users", "articles", "comments"}
+
+// Columns are the columns of the tables in the order Reader returns them
+var Columns = map[string][]string{
+	"users":    {"id", "name", "description"},
+	"articles": {"id", "author_id", "title", "text"},
+	"comments": {"id", "author_id", "article_id", "title", "text"},
+}
+
+// Source is the file with the rows of a table.
+type Source struct {
+	Path string `json:"path"`
+	// Format is csv or jsonl, it is derived from the extension of Path if it
+	// is not set
+	Format string `json:"format,omitempty"`
+	// Columns maps the columns of the table to the columns of the file, e.g.
+	// {"name": "full_name"}, the other columns are named like in the table
+	Columns map[string]string `json:"columns,omitempty"`
+}
+
+func (s Source) format() string {
+	if s.Format != "" {
+		return strings.ToLower(s.Format)
+	}
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".csv":
+		return CSV
+	case ".jsonl", ".ndjson", ".json":
+		return JSONL
+	}
+	return ""
+}
+
+// Sources are the files of the imported tables, keyed by table.
+type Sources map[string]Source
+
+// Find looks for the files named like the tables, e.g. users.csv or
+// articles.jsonl, in dir.
+func Find(dir string) (Sources, error) {
+	sources := Sources{}
+	for _, table := range Tables {
+		for _, extension := range []string{".csv", ".jsonl", ".ndjson", ".json"} {
+			path := filepath.Join(dir, table+extension)
+			if _, err := os.Stat(path); err == nil {
+				sources[table] = Source{Path: path}
+				break
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no users, articles or comments files in %s", dir)
+	}
+	return sources, nil
+}
+
+func (s Sources) Validate() error {
+	for table, source := range s {
+		columns, ok := Columns[table]
+		if !ok {
+			return fmt.Errorf("can not import %s, expected one of %s", table, strings.Join(Tables, ", "))
+		}
+		if source.Path == "" {
+			return fmt.Errorf("import %s: no path", table)
+		}
+		if format := source.format(); format != CSV && format != JSONL {
+			return fmt.Errorf("import %s: unknown format of %s, expected csv or jsonl", table, source.Path)
+		}
+		for column := range source.Columns {
+			if !contains(columns, column) {
+				return fmt.Errorf("import %s: unknown column %s, expected one of %s", table, column, strings.Join(columns, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Tables are the imported tables in the order they are loaded
+func (s Sources) Tables() []string {
+	var tables []string
+	for _, table := range Tables {
+		if _, ok := s[table]; ok {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
+
+func (s Sources) String() string {
+	var files []string
+	for _, table := range s.Tables() {
+		files = append(files, s[table].Path)
+	}
+	return strings.Join(files, ", ")
+}
+
+// Reader reads the rows of a table from its file, ids are ints and the other
+// columns strings.
+type Reader struct {
+	table   string
+	columns []string
+	file    *os.File
+	line    int
+	next    func() ([]interface{}, error)
+}
+
+// Open opens the file of table.
+func Open(table string, source Source) (*Reader, error) {
+	if err := (Sources{table: source}).Validate(); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(source.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reader{table: table, columns: Columns[table], file: file}
+	names := make([]string, len(r.columns))
+	for i, column := range r.columns {
+		names[i] = column
+		if name, ok := source.Columns[column]; ok {
+			names[i] = name
+		}
+	}
+
+	if source.format() == CSV {
+		err = r.openCSV(names)
+	} else {
+		r.openJSONL(names)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", source.Path, err)
+	}
+	return r, nil
+}
+
+func (r *Reader) openCSV(names []string) error {
+	reader := csv.NewReader(bufio.NewReader(r.file))
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("header: %w", err)
+	}
+	r.line++
+
+	indexes := make([]int, len(names))
+	for i, name := range names {
+		indexes[i] = -1
+		for j, field := range header {
+			if strings.TrimSpace(field) == name {
+				indexes[i] = j
+			}
+		}
+		if indexes[i] < 0 {
+			return fmt.Errorf("header: no column %s, found %s", name, strings.Join(header, ", "))
+		}
+	}
+
+	r.next = func() ([]interface{}, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(names))
+		for i, index := range indexes {
+			if row[i], err = r.value(i, record[index]); err != nil {
+				return nil, err
+			}
+		}
+		return row, nil
+	}
+	return nil
+}
+
+func (r *Reader) openJSONL(names []string) {
+	decoder := json.NewDecoder(bufio.NewReader(r.file))
+	decoder.UseNumber()
+
+	r.next = func() ([]interface{}, error) {
+		var document map[string]interface{}
+		if err := decoder.Decode(&document); err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(names))
+		for i, name := range names {
+			field, ok := document[name]
+			if !ok || field == nil {
+				return nil, fmt.Errorf("no value of %s", name)
+			}
+			var err error
+			if row[i], err = r.value(i, fmt.Sprint(field)); err != nil {
+				return nil, err
+			}
+		}
+		return row, nil
+	}
+}
+
+// value converts the field of column i, ids to ints
+func (r *Reader) value(i int, field string) (interface{}, error) {
+	column := r.columns[i]
+	if column != "id" && !strings.HasSuffix(column, "_id") {
+		return field, nil
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(field))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an integer: %q", column, field)
+	}
+	return id, nil
+}
+
+// Next returns the values of the next row in the order of Columns, io.EOF
+// after the last one.
+func (r *Reader) Next() ([]interface{}, error) {
+	r.line++
+	row, err := r.next()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%s line %d: %w", r.file.Name(), r.line, err)
+	}
+	return row, err
+}
+
+func (r *Reader) Table() string {
+	return r.table
+}
+
+func (r *Reader) Columns() []string {
+	return r.columns
+}
+
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package fixture
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readAll(t *testing.T, table string, source Source) [][]interface{} {
+	t.Helper()
+	reader, err := Open(table, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var rows [][]interface{}
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return rows
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVColumnsAreMapped(t *testing.T) {
+	path := write(t, t.TempDir(), "people.csv", "bio,full_name,id\n\"multi\nline\",\"Doe, Jane\",7\n,Bob, 8\n")
+	rows := readAll(t, "users", Source{Path: path, Columns: map[string]string{"name": "full_name", "description": "bio"}})
+
+	want := [][]interface{}{{7, "Doe, Jane", "multi\nline"}, {8, "Bob", ""}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows %v, want %v", rows, want)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	path := write(t, t.TempDir(), "comments.data", `{"id": 1, "author_id": 2, "article_id": 3, "title": "hi", "text": "there", "extra": true}
+{"id": "4", "author_id": 5, "article_id": 6, "title": 7, "text": "ü"}
+`)
+	rows := readAll(t, "comments", Source{Path: path, Format: "JSONL"})
+
+	want := [][]interface{}{{1, 2, 3, "hi", "there"}, {4, 5, 6, "7", "ü"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("rows %v, want %v", rows, want)
+	}
+}
+
+func TestInvalidRowsReportTheLine(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		name, content, want string
+	}{
+		{"users.csv", "id,name,description\n1,a,b\nx,c,d\n", "line 3: id is not an integer"},
+		{"articles.jsonl", "{\"id\": 1, \"author_id\": 1, \"title\": \"t\", \"text\": \"x\"}\n{\"id\": 2, \"title\": \"t\", \"text\": \"x\"}\n", "line 2: no value of author_id"},
+		{"articles.json", "{\"id\": 1.5, \"author_id\": 1, \"title\": \"t\", \"text\": \"x\"}\n", "line 1: id is not an integer"},
+	} {
+		table := strings.TrimSuffix(test.name, filepath.Ext(test.name))
+		reader, err := Open(table, Source{Path: write(t, dir, test.name, test.content)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for err == nil {
+			_, err = reader.Next()
+		}
+		reader.Close()
+		if !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("%s: error %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestOpenRejectsInvalidSources(t *testing.T) {
+	dir := t.TempDir()
+	users := write(t, dir, "users.csv", "id,name\n1,a\n")
+	for _, test := range []struct {
+		table  string
+		source Source
+		want   string
+	}{
+		{"users", Source{Path: users}, "no column description"},
+		{"posts", Source{Path: users}, "can not import posts"},
+		{"users", Source{Path: write(t, dir, "users.xml", "")}, "unknown format"},
+		{"users", Source{Path: users, Columns: map[string]string{"email": "mail"}}, "unknown column email"},
+	} {
+		if _, err := Open(test.table, test.source); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Fatalf("%s %+v: error %v, want %q", test.table, test.source, err, test.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "users.csv", "")
+	write(t, dir, "comments.jsonl", "")
+	write(t, dir, "notes.csv", "")
+
+	sources, err := Find(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tables := sources.Tables(); !reflect.DeepEqual(tables, []string{"users", "comments"}) {
+		t.Fatalf("found %v", tables)
+	}
+	if _, err := Find(t.TempDir()); err == nil {
+		t.Fatal("expected an error for a directory without files")
+	}
+}
@@ -0,0 +1,102 @@
+package fixture
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Copy is the text format of the Postgres COPY command, tab separated
+// columns without a header
+const Copy = "copy"
+
+// Extensions are the file extensions of the formats, the csv and jsonl files
+// can be imported again
+var Extensions = map[string]string{CSV: ".csv", JSONL: ".jsonl", Copy: ".copy"}
+
+// copyEscaper escapes the values for the text format of COPY
+var copyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// Writer writes the rows of a table in one of the formats.
+type Writer struct {
+	columns []string
+	format  string
+	out     *bufio.Writer
+	csv     *csv.Writer
+	fields  []string
+}
+
+// NewWriter creates the writer of the rows of table in format, the values of
+// the rows are in the order of Columns.
+func NewWriter(w io.Writer, table, format string) (*Writer, error) {
+	columns, ok := Columns[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s, expected one of %s", table, strings.Join(Tables, ", "))
+	}
+	if _, ok := Extensions[format]; !ok {
+		return nil, fmt.Errorf("unknown format %q, expected csv, jsonl or copy", format)
+	}
+
+	writer := &Writer{columns: columns, format: format, out: bufio.NewWriter(w), fields: make([]string, len(columns))}
+	if format == CSV {
+		writer.csv = csv.NewWriter(writer.out)
+		if err := writer.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+func (w *Writer) Write(row []interface{}) error {
+	for i, value := range row {
+		w.fields[i] = fmt.Sprint(value)
+	}
+
+	switch w.format {
+	case CSV:
+		return w.csv.Write(w.fields)
+	case Copy:
+		for i, field := range w.fields {
+			if i > 0 {
+				w.out.WriteByte('\t')
+			}
+			copyEscaper.WriteString(w.out, field)
+		}
+		return w.out.WriteByte('\n')
+	}
+
+	// the columns are written in the order of the table, unlike a map
+	w.out.WriteByte('{')
+	for i, column := range w.columns {
+		if i > 0 {
+			w.out.WriteByte(',')
+		}
+		w.out.WriteString(strconv.Quote(column) + ":")
+		if _, ok := row[i].(int); ok {
+			w.out.WriteString(w.fields[i])
+			continue
+		}
+		value, err := json.Marshal(w.fields[i])
+		if err != nil {
+			return err
+		}
+		w.out.Write(value)
+	}
+	w.out.WriteByte('}')
+	return w.out.WriteByte('\n')
+}
+
+// Flush writes the buffered rows
+func (w *Writer) Flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
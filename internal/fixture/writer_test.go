@@ -0,0 +1,59 @@
+package fixture
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestWrittenRowsAreReadBack(t *testing.T) {
+	rows := [][]interface{}{
+		{1, 2, 3, "a, \"quoted\" title", "multi\nline\ttext \\ ü"},
+		{4, 5, 6, "", "{\"json\": true}"},
+	}
+	for _, format := range []string{CSV, JSONL} {
+		var out bytes.Buffer
+		writer, err := NewWriter(&out, "comments", format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range rows {
+			if err := writer.Write(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		path := write(t, t.TempDir(), "comments"+Extensions[format], out.String())
+		if read := readAll(t, "comments", Source{Path: path}); !reflect.DeepEqual(read, rows) {
+			t.Fatalf("%s: read %v, want %v", format, read, rows)
+		}
+	}
+}
+
+func TestCopyTextEscapes(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(&out, "users", Copy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Write([]interface{}{7, "tab\there", "back\\slash\r\nnew line"})
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "7\ttab\\there\tback\\\\slash\\r\\nnew line\n"; out.String() != want {
+		t.Fatalf("copy text %q, want %q", out.String(), want)
+	}
+}
+
+func TestNewWriterRejectsUnknownTablesAndFormats(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "posts", CSV); err == nil {
+		t.Fatal("expected an error for an unknown table")
+	}
+	if _, err := NewWriter(&bytes.Buffer{}, "users", "xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
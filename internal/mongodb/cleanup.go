@@ -0,0 +1,45 @@
+package mongodb
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"sort"
+	"time"
+)
+
+// Cleanup drops the databases of the runs started before cutoff that were
+// kept or not removed, e.g. after a crash, and returns their names. With
+// dryRun it only lists them.
+func Cleanup(ctx context.Context, uri string, cutoff time.Time, dryRun bool) ([]string, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		disconnect, cancel := cleanupContext()
+		defer cancel()
+		client.Disconnect(disconnect)
+	}()
+
+	names, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var dropped []string
+	for _, name := range bench.Leftovers(names, cutoff) {
+		if !dryRun {
+			if err := client.Database(name).Drop(ctx); err != nil {
+				return dropped, err
+			}
+			log.Printf("Dropped database %s", name)
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
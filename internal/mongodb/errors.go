@@ -0,0 +1,40 @@
+package mongodb
+
+import (
+	"errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"postgres_performance_test/internal/bench"
+)
+
+// server error codes, see https://www.mongodb.com/docs/manual/reference/error-codes/
+const (
+	writeConflict             = 112
+	documentValidationFailure = 121
+)
+
+func classify(err error) bench.ErrorClass {
+	var serverErr mongo.ServerError
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		return bench.DuplicateKey
+	case mongo.IsTimeout(err):
+		return bench.Timeout
+	case mongo.IsNetworkError(err):
+		return bench.ConnectionReset
+	case errors.As(err, &serverErr) && serverErr.HasErrorCode(writeConflict):
+		return bench.SerializationFailure
+	case errors.As(err, &serverErr) && serverErr.HasErrorCode(documentValidationFailure):
+		return bench.ConstraintViolation
+	}
+	return bench.ClassifyTransport(err)
+}
+
+// retryable accepts the errors the server labels as retryable and network errors
+func retryable(err error) bool {
+	var labeled interface{ HasErrorLabel(string) bool }
+	if errors.As(err, &labeled) &&
+		(labeled.HasErrorLabel("RetryableWriteError") || labeled.HasErrorLabel("TransientTransactionError")) {
+		return true
+	}
+	return mongo.IsNetworkError(err) || bench.ClassifyTransport(err) == bench.ConnectionReset
+}
@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"io"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/fixture"
+)
+
+// importBatch is the number of documents per insertMany of the import
+const importBatch = 1000
+
+// importIndexes are the indexes the insert scenarios create after the load
+var importIndexes = map[string][]string{
+	"users":    {"_id", "name", "description"},
+	"articles": {"_id", "author_id"},
+	"comments": {"_id", "author_id", "article_id"},
+}
+
+// Import inserts the documents of the fixture with unordered insertMany
+// batches. The documents get new object ids, the ids of the file are mapped
+// to them, so the articles and comments must be imported after the users and
+// articles they reference.
+func (b *Backend) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	collection := b.Database().Collection(table)
+	opts := options.InsertMany().SetOrdered(false)
+
+	var imported int64
+	for done := false; !done && ctx.Err() == nil; {
+		var documents []interface{}
+		checksum := &bench.Checksum{}
+		ids := map[int]string{}
+		for len(documents) < importBatch {
+			row, err := rows.Next()
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				return imported, err
+			}
+
+			id := primitive.NewObjectID()
+			document, err := b.document(table, id, row, checksum)
+			if err != nil {
+				return imported, err
+			}
+			documents = append(documents, document)
+			ids[row[0].(int)] = id.Hex()
+		}
+		if len(documents) == 0 {
+			break
+		}
+
+		res, err := collection.InsertMany(ctx, documents, opts)
+		if err != nil {
+			return imported, err
+		}
+		imported += int64(len(res.InsertedIDs))
+		b.checksums.Table(table).Merge(checksum)
+		b.expectedRows[table] += int64(len(res.InsertedIDs))
+		for key, id := range ids {
+			b.container(table).Add(key, id)
+		}
+	}
+
+	for _, key := range importIndexes[table] {
+		if err := AddIndex(collection, ctx, key); err != nil {
+			return imported, err
+		}
+	}
+	if table == "comments" {
+		b.articlesIdContainer = NewContainer()
+	}
+	b.profile = b.profile.Imported(table, imported)
+	return imported, nil
+}
+
+// container maps the ids of the rows of table to the object ids of their documents
+func (b *Backend) container(table string) *Container {
+	if table == "users" {
+		return b.usersIdContainer
+	}
+	return b.articlesIdContainer
+}
+
+// document is the document of the row of table, the references are looked up
+// among the imported documents
+func (b *Backend) document(table string, id primitive.ObjectID, row []interface{}, checksum *bench.Checksum) (interface{}, error) {
+	reference := func(container *Container, key interface{}) (primitive.ObjectID, error) {
+		objectID, err := primitive.ObjectIDFromHex(container.GetByKey(key.(int)))
+		if err != nil {
+			return objectID, fmt.Errorf("%s %d references %v, which was not imported", table, row[0], key)
+		}
+		return objectID, nil
+	}
+
+	switch table {
+	case "users":
+		checksum.Add(id.Hex(), row[1], row[2])
+		return bson.D{{Key: "_id", Value: id}, {Key: "name", Value: row[1]}, {Key: "description", Value: row[2]}}, nil
+	case "articles":
+		authorID, err := reference(b.usersIdContainer, row[1])
+		if err != nil {
+			return nil, err
+		}
+		checksum.Add(id.Hex(), authorID.Hex(), row[2], row[3])
+		return &Article{ID: id, AuthorId: authorID, Title: row[2].(string), Description: row[3].(string)}, nil
+	default:
+		authorID, err := reference(b.usersIdContainer, row[1])
+		if err != nil {
+			return nil, err
+		}
+		articleID, err := reference(b.articlesIdContainer, row[2])
+		if err != nil {
+			return nil, err
+		}
+		checksum.Add(id.Hex(), articleID.Hex(), authorID.Hex(), row[3], row[4])
+		return &Comment{ID: id, ArticleId: articleID, AuthorId: authorID, Title: row[3].(string), Text: row[4].(string)}, nil
+	}
+}
@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"time"
+)
+
+// payloadCollection holds the articles of the payload sweep, it is created
+// again for every size, so its size is the footprint of a single size. The
+// articles collection keeps the loaded documents, which would be counted too.
+const payloadCollection = "payload_sweep"
+
+// payloadNote tells in the report why the sweep does not use articles
+const payloadNote = "articles in " + payloadCollection + ", a collection per size, so the storage is that of the payloads only"
+
+// payloadStorage are the collStats fields of the payload collection, size is
+// the BSON size of the documents and storageSize what WiredTiger keeps on
+// disk after compression
+type payloadStorage struct {
+	Size           int64 `bson:"size"`
+	AvgObjSize     int64 `bson:"avgObjSize"`
+	StorageSize    int64 `bson:"storageSize"`
+	TotalIndexSize int64 `bson:"totalIndexSize"`
+}
+
+// payloadScenarios insert and read article documents with texts of the
+// payload sizes, a document is limited to 16 MB
+func (b *Backend) payloadScenarios() []bench.Scenario {
+	return bench.PayloadSweep(b.payloadSizes, b.preparePayloads, b.dropPayloads, b.insertPayloads, b.selectPayloads)
+}
+
+func (b *Backend) preparePayloads(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := b.Database().Collection(payloadCollection).Drop(ctx); err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+func (b *Backend) dropPayloads(ctx context.Context, errs *bench.Errors) {
+	if err := b.Database().Collection(payloadCollection).Drop(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("drop %s: %v", payloadCollection, err)
+	}
+}
+
+func (b *Backend) insertPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "insert payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== INSERT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Insert %d articles with %s of text in progress...", rows, name)
+
+			collection := b.Database().Collection(payloadCollection)
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				authorId, err := primitive.ObjectIDFromHex(b.usersIdContainer.GetByKey(b.profile.AuthorOf(position)))
+				if err != nil {
+					errs.Record(err)
+					return err
+				}
+				document := bson.D{
+					{Key: "_id", Value: position},
+					{Key: "author_id", Value: authorId},
+					{Key: "title", Value: b.data.Text("articles.title", position)},
+					{Key: "description", Value: b.data.Payload(size, position)},
+				}
+				return errs.Do(ctx, func() error {
+					_, err := collection.InsertOne(ctx, document)
+					return err
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+		Note: payloadNote,
+		Storage: func(ctx context.Context) (bench.TableSize, error) {
+			var stats payloadStorage
+			found, err := collStats(ctx, b.Database(), payloadCollection, &stats)
+			if err != nil {
+				return bench.TableSize{}, err
+			}
+			if !found {
+				return bench.TableSize{}, fmt.Errorf("collection %s not found", payloadCollection)
+			}
+			log.Printf("%s: %s of documents, %s per document, %s on disk", payloadCollection,
+				bench.FormatBytes(stats.Size), bench.FormatBytes(stats.AvgObjSize), bench.FormatBytes(stats.StorageSize))
+			return bench.TableSize{
+				Name:    payloadCollection,
+				Heap:    stats.StorageSize,
+				Indexes: stats.TotalIndexSize,
+				Total:   stats.StorageSize + stats.TotalIndexSize,
+			}, nil
+		},
+	}
+}
+
+func (b *Backend) selectPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "select payload " + name,
+		Note: payloadNote,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== SELECT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Select %d articles with %s of text in progress...", rows, name)
+
+			collection := b.Database().Collection(payloadCollection)
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				id := b.data.Intn("select payload", position, rows)
+				return errs.Do(ctx, func() error {
+					var document struct {
+						Title       string `bson:"title"`
+						Description string `bson:"description"`
+					}
+					return collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&document)
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+	}
+}
@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"sort"
+)
+
+type collectionStats struct {
+	StorageSize    int64            `bson:"storageSize"`
+	TotalIndexSize int64            `bson:"totalIndexSize"`
+	IndexSizes     map[string]int64 `bson:"indexSizes"`
+}
+
+func storageReport(ctx context.Context, db *mongo.Database, title string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	tables, err := collectionSizes(ctx, db)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Storage report failed: %v", err)
+		}
+		return
+	}
+	bench.LogStorage(title, tables)
+}
+
+// collectionStorage measures the footprint of the collection after the
+// scenario that loads it
+func (b *Backend) collectionStorage(name string) func(ctx context.Context) (bench.TableSize, error) {
+	return func(ctx context.Context) (bench.TableSize, error) {
+		table, found, err := collectionSize(ctx, b.Database(), name)
+		if err != nil {
+			return bench.TableSize{}, err
+		}
+		if !found {
+			return bench.TableSize{}, fmt.Errorf("collection %s not found", name)
+		}
+		return table, nil
+	}
+}
+
+func collectionSizes(ctx context.Context, db *mongo.Database) ([]bench.TableSize, error) {
+	var tables []bench.TableSize
+	for _, name := range telemetryCollections {
+		table, found, err := collectionSize(ctx, db, name)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// collectionSize is the footprint of a collection from its collStats, found
+// is false when the collection has not been created yet
+func collectionSize(ctx context.Context, db *mongo.Database, name string) (table bench.TableSize, found bool, err error) {
+	var stats collectionStats
+	found, err = collStats(ctx, db, name, &stats)
+	if err != nil || !found {
+		return bench.TableSize{}, found, err
+	}
+
+	table = bench.TableSize{
+		Name:    name,
+		Heap:    stats.StorageSize,
+		Indexes: stats.TotalIndexSize,
+		Total:   stats.StorageSize + stats.TotalIndexSize,
+	}
+	for indexName, size := range stats.IndexSizes {
+		table.IndexSizes = append(table.IndexSizes, bench.IndexSize{Name: indexName, Size: size})
+	}
+	sort.Slice(table.IndexSizes, func(i, j int) bool {
+		return table.IndexSizes[i].Name < table.IndexSizes[j].Name
+	})
+	return table, true, nil
+}
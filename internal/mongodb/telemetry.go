@@ -0,0 +1,204 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"sort"
+	"strings"
+)
+
+// namespaceNotFound is returned by collStats for a collection that does not exist yet
+const namespaceNotFound = 26
+
+var telemetryCollections = []string{"users", "articles", "comments"}
+
+// serverStatus sections we keep from the (very large) serverStatus document
+var serverStatusSections = []string{"opcounters", "wiredTiger.cache", "locks", "network"}
+
+var collStatsFields = []string{"count", "size", "storageSize", "totalIndexSize", "indexSizes"}
+
+var dbStatsFields = []string{"objects", "dataSize", "storageSize", "indexSize"}
+
+// snapshot is a flat view of the server-side counters, keyed by dotted path,
+// e.g. "serverStatus.opcounters.insert" or "collStats.users.storageSize".
+type snapshot map[string]float64
+
+// BeforeScenario snapshots the server-side counters the scenario is compared to
+func (b *Backend) BeforeScenario(ctx context.Context, name string) {
+	before, err := takeSnapshot(ctx, b.Database())
+	if err != nil {
+		log.Printf("Telemetry snapshot before %s failed: %v", name, err)
+	}
+	b.before = before
+}
+
+// storagePrefix starts the keys of the storage and index sizes after the
+// scenario in the telemetry of a result, the other keys are deltas
+const storagePrefix = "storage."
+
+// AfterScenario adds the server-side telemetry deltas of the scenario and the
+// storage and index sizes after it to its result. Without a snapshot before
+// the scenario there is no delta, only the sizes are added.
+func (b *Backend) AfterScenario(ctx context.Context, result *bench.Result) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	after, err := takeSnapshot(ctx, b.Database())
+	if err != nil {
+		log.Printf("Telemetry snapshot after %s failed: %v", result.Name, err)
+		return
+	}
+	var delta snapshot
+	if b.before != nil {
+		delta = after.delta(b.before)
+	}
+	storage := after.storage()
+
+	result.Telemetry = map[string]float64{}
+	for key, value := range delta {
+		result.Telemetry[key] = value
+	}
+	for key, value := range storage {
+		result.Telemetry[storagePrefix+key] = value
+	}
+	logTelemetry(result.Name, delta, storage)
+}
+
+func takeSnapshot(ctx context.Context, db *mongo.Database) (snapshot, error) {
+	snap := snapshot{}
+
+	var status bson.M
+	err := db.Client().Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&status)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range serverStatusSections {
+		snap.flatten("serverStatus."+section, lookup(status, section))
+	}
+
+	for _, name := range telemetryCollections {
+		var stats bson.M
+		found, err := collStats(ctx, db, name, &stats)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		for _, field := range collStatsFields {
+			snap.flatten("collStats."+name+"."+field, stats[field])
+		}
+	}
+
+	var stats bson.M
+	err = db.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range dbStatsFields {
+		snap.flatten("dbStats."+field, stats[field])
+	}
+
+	return snap, nil
+}
+
+// collStats decodes the collStats of a collection into result, found is false
+// when the collection has not been created yet
+func collStats(ctx context.Context, db *mongo.Database, name string, result interface{}) (found bool, err error) {
+	err = db.RunCommand(ctx, bson.D{{Key: "collStats", Value: name}}).Decode(result)
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.HasErrorCode(namespaceNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// lookup walks a dotted path through nested documents
+func lookup(doc interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		switch d := doc.(type) {
+		case bson.M:
+			doc = d[key]
+		case bson.D:
+			doc = d.Map()[key]
+		default:
+			return nil
+		}
+	}
+	return doc
+}
+
+// flatten stores every numeric leaf of value under prefix
+func (s snapshot) flatten(prefix string, value interface{}) {
+	switch v := value.(type) {
+	case bson.M:
+		for key, nested := range v {
+			s.flatten(prefix+"."+key, nested)
+		}
+	case bson.D:
+		for _, e := range v {
+			s.flatten(prefix+"."+e.Key, e.Value)
+		}
+	case int32:
+		s[prefix] = float64(v)
+	case int64:
+		s[prefix] = float64(v)
+	case float64:
+		s[prefix] = v
+	}
+}
+
+func (s snapshot) delta(before snapshot) snapshot {
+	delta := snapshot{}
+	for key, value := range s {
+		if diff := value - before[key]; diff != 0 {
+			delta[key] = diff
+		}
+	}
+	return delta
+}
+
+// storage returns the storage and index sizes of the collections and the database
+func (s snapshot) storage() snapshot {
+	storage := snapshot{}
+	for key, value := range s {
+		if strings.HasPrefix(key, "collStats.") || strings.HasPrefix(key, "dbStats.") {
+			storage[key] = value
+		}
+	}
+	return storage
+}
+
+func (s snapshot) keys() []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// logTelemetry prints the delta and the sizes, a nil delta means there was
+// no snapshot before the scenario
+func logTelemetry(name string, delta, storage snapshot) {
+	log.Printf("------- %s: server telemetry delta -------", name)
+	if delta == nil {
+		log.Print("no snapshot before the scenario, delta skipped")
+	}
+	for _, key := range delta.keys() {
+		log.Printf("%-70s %+.0f", key, delta[key])
+	}
+	log.Printf("------- %s: storage and index sizes -------", name)
+	for _, key := range storage.keys() {
+		log.Printf("%-70s %.0f", key, storage[key])
+	}
+	log.Print("==============================")
+}
@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"log"
+	"postgres_performance_test/internal/bench"
+)
+
+// verifyIntegrity is enabled by Options.Verify, it fails the run if the loaded
+// documents reference missing documents or do not match the checksums of the
+// inserted documents
+func (b *Backend) verifyIntegrity(ctx context.Context, errs *bench.Errors) {
+	if !b.verify || ctx.Err() != nil {
+		return
+	}
+
+	log.Print("========== VERIFY INTEGRITY ============")
+
+	db := b.Database()
+	err := bench.RunIntegrityChecks(b.integrityChecks(ctx, db))
+	if err == nil {
+		err = b.checksums.Verify(func(collection string) (*bench.Checksum, error) {
+			return readChecksum(ctx, db, collection)
+		})
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			errs.Fail(err)
+		}
+		return
+	}
+
+	log.Print("Integrity verified")
+	log.Print("==============================")
+}
+
+func (b *Backend) integrityChecks(ctx context.Context, db *mongo.Database) []bench.IntegrityCheck {
+	var checks []bench.IntegrityCheck
+	add := func(name, collection, localField, from string) {
+		checks = append(checks, bench.IntegrityCheck{Name: name, Count: func() (int64, error) {
+			return countOrphans(ctx, db, collection, localField, from)
+		}})
+	}
+
+	if _, ok := b.expectedRows["articles"]; ok {
+		add("articles without author", "articles", "author_id", "users")
+	}
+	if _, ok := b.expectedRows["comments"]; ok {
+		add("comments without author", "comments", "author_id", "users")
+		add("comments without article", "comments", "article_id", "articles")
+	}
+
+	return checks
+}
+
+// countOrphans counts the documents of collection whose localField does not
+// match the _id of any document in from
+func countOrphans(ctx context.Context, db *mongo.Database, collection, localField, from string) (int64, error) {
+	cursor, err := db.Collection(collection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: from},
+			{Key: "localField", Value: localField},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "referenced"},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "referenced", Value: bson.D{{Key: "$size", Value: 0}}}}}},
+		{{Key: "$count", Value: "orphans"}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Orphans int64 `bson:"orphans"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Orphans, cursor.Err()
+}
+
+// readChecksum computes the checksum of the documents stored in collection,
+// the fields are hashed in the order the insert scenarios pass them to Checksum.Add
+func readChecksum(ctx context.Context, db *mongo.Database, collection string) (*bench.Checksum, error) {
+	cursor, err := db.Collection(collection).Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	checksum := &bench.Checksum{}
+	for cursor.Next(ctx) {
+		switch collection {
+		case "users":
+			var user struct {
+				ID          primitive.ObjectID `bson:"_id"`
+				Name        string             `bson:"name"`
+				Description string             `bson:"description"`
+			}
+			if err := cursor.Decode(&user); err != nil {
+				return nil, err
+			}
+			checksum.Add(user.ID.Hex(), user.Name, user.Description)
+		case "articles":
+			var article Article
+			if err := cursor.Decode(&article); err != nil {
+				return nil, err
+			}
+			checksum.Add(article.ID.Hex(), article.AuthorId.Hex(), article.Title, article.Description)
+		case "comments":
+			var comment Comment
+			if err := cursor.Decode(&comment); err != nil {
+				return nil, err
+			}
+			checksum.Add(comment.ID.Hex(), comment.ArticleId.Hex(), comment.AuthorId.Hex(), comment.Title, comment.Text)
+		}
+	}
+	return checksum, cursor.Err()
+}
@@ -0,0 +1,80 @@
+package mysql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"postgres_performance_test/internal/bench"
+)
+
+// server error numbers, see the MySQL server error reference
+const (
+	errDupEntry         = 1062
+	errBadNull          = 1048
+	errNoReferencedRow  = 1216
+	errRowIsReferenced  = 1217
+	errRowIsReferenced2 = 1451
+	errNoReferencedRow2 = 1452
+	errCheckConstraint  = 3819
+	errLockWaitTimeout  = 1205
+	errLockDeadlock     = 1213
+	errQueryTimeout     = 3024
+	errConCount         = 1040
+	errServerShutdown   = 1053
+	errConnectionKilled = 1927
+	errParse            = 1064
+	errAlterNotSupp     = 1845
+	errAlterNotSuppWhy  = 1846
+)
+
+// classify maps MySQL errors to error classes by their error numbers
+func classify(err error) bench.ErrorClass {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errDupEntry:
+			return bench.DuplicateKey
+		case errBadNull, errRowIsReferenced, errNoReferencedRow, errRowIsReferenced2, errNoReferencedRow2, errCheckConstraint:
+			return bench.ConstraintViolation
+		case errLockWaitTimeout, errLockDeadlock:
+			return bench.SerializationFailure
+		case errQueryTimeout:
+			// raised by max_execution_time
+			return bench.Timeout
+		case errConCount, errServerShutdown, errConnectionKilled:
+			return bench.ConnectionReset
+		}
+	}
+	if errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) {
+		return bench.ConnectionReset
+	}
+	return bench.ClassifyTransport(err)
+}
+
+// retryable accepts deadlocks, lock wait timeouts and connection errors
+func retryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errLockWaitTimeout, errLockDeadlock, errConCount, errServerShutdown, errConnectionKilled:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, driver.ErrBadConn) ||
+		bench.ClassifyTransport(err) == bench.ConnectionReset
+}
+
+// rejectsAlgorithm is true for the errors of servers that do not run the
+// ALTER TABLE with the requested ALGORITHM, the servers before instant DDL do
+// not parse ALGORITHM=INSTANT
+func rejectsAlgorithm(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errParse, errAlterNotSupp, errAlterNotSuppWhy:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"io"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/fixture"
+	"strings"
+)
+
+// Import streams the rows of the fixture to LOAD DATA LOCAL INFILE like the
+// bulk load. The driver ends the file early if a row can not be read and the
+// statement loads the rows before it, so it runs in a transaction that is
+// rolled back then.
+func (b *Backend) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	reader, writer := io.Pipe()
+	// closing the reader stops the writer if the statement fails before reading everything
+	defer reader.Close()
+
+	checksum := &bench.Checksum{}
+	read := make(chan error, 1)
+	go func() {
+		read <- writeRows(writer, rows, checksum)
+	}()
+
+	handler := "import_" + table
+	mysql.RegisterReaderHandler(handler, func() io.Reader {
+		return reader
+	})
+	defer mysql.DeregisterReaderHandler(handler)
+
+	query := fmt.Sprintf("LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 (%s)", handler, table, strings.Join(rows.Columns(), ", "))
+	res, err := tx.ExecContext(ctx, query)
+	reader.Close()
+	if readErr := <-read; readErr != nil {
+		return 0, readErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	imported, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	b.expectedRows[table] += imported
+	b.checksums.Table(table).Merge(checksum)
+	b.profile = b.profile.Imported(table, imported)
+	return imported, nil
+}
+
+// writeRows writes the rows in the default format of LOAD DATA, a failed row
+// fails the statement
+func writeRows(writer *io.PipeWriter, rows *fixture.Reader, checksum *bench.Checksum) error {
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return writer.Close()
+		}
+		if err != nil {
+			writer.CloseWithError(err)
+			return err
+		}
+
+		fields := make([]string, len(row))
+		for i, value := range row {
+			fields[i] = loadFieldEscaper.Replace(fmt.Sprint(value))
+		}
+		if _, err := io.WriteString(writer, strings.Join(fields, "\t")+"\n"); err != nil {
+			// the statement stopped reading, its error is reported
+			return nil
+		}
+		checksum.Add(row...)
+	}
+}
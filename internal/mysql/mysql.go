@@ -0,0 +1,750 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"io"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/migration"
+	"strings"
+	"time"
+)
+
+var tables = []string{"users", "articles", "articles_simple", "comments", "comments_simple"}
+
+const selectWithJoinsQuery = `SELECT *
+		 FROM users
+         JOIN articles ON articles.author_id = users.id
+		 JOIN comments ON comments.author_id = users.id
+		 LIMIT 50 OFFSET 1;
+         `
+
+const selectWithFiltersQuery = `SELECT *
+		 FROM users
+         WHERE id > ?
+		 LIMIT 50 OFFSET 1;
+         `
+
+const selectWithJoinsAndFiltersQuery = `SELECT *
+		 FROM users
+         JOIN articles ON articles.author_id = users.id
+		 JOIN comments ON comments.author_id = users.id
+		 WHERE comments.id > ?
+		 LIMIT 50 OFFSET 1;
+         `
+
+// DefaultDSN is the database started by docker-compose.yml, the migrations
+// need multiStatements. The tables of every run are in a database of their
+// own next to it.
+const DefaultDSN = "test:test@tcp(localhost:3306)/test?multiStatements=true"
+
+// multiRowBatch is the number of rows per INSERT of the multi-row bulk load
+const multiRowBatch = 1000
+
+// Backend runs the scenarios against MySQL or MariaDB, it holds the state of a single run.
+type Backend struct {
+	dsn          string
+	db           *sql.DB
+	database     string
+	keep         bool
+	profile      bench.Profile
+	poolCount    int
+	verify       bool
+	data         *datagen.Generator
+	expectedRows bench.ExpectedRows
+	checksums    bench.Checksums
+	// generated are the rows per table the insert scenarios generated at the
+	// positions from 0, their titles are verified
+	generated    map[string]int
+	payloadSizes []int
+}
+
+func New(dsn string) *Backend {
+	return &Backend{dsn: dsn}
+}
+
+func (b *Backend) Name() string {
+	return "MYSQL"
+}
+
+// DB is the connection pool of the run, for custom scenarios
+func (b *Backend) DB() *sql.DB {
+	return b.db
+}
+
+func (b *Backend) Setup(ctx context.Context, options bench.Options, errs *bench.Errors) error {
+	// the migrations of this dialect have a DDL of their own
+	if !options.Schema.IsDefault() {
+		return fmt.Errorf("%s has no schema variants, can not create %s", b.Name(), options.Schema)
+	}
+	b.profile = options.Dataset()
+	b.poolCount = options.Workers
+	b.verify = options.Verify
+	b.data = options.Generator()
+	if err := b.data.CheckNullable(nil); err != nil {
+		return err
+	}
+	b.payloadSizes = options.PayloadSizes
+	b.expectedRows = bench.ExpectedRows{}
+	b.generated = map[string]int{}
+	b.checksums = bench.Checksums{}
+	for _, table := range tables {
+		b.checksums.Table(table)
+	}
+
+	b.db = nil
+	b.keep = false
+	if options.RunID == "" {
+		return fmt.Errorf("no run id, the database of the run is named after it")
+	}
+	b.database = bench.RunPrefix + options.RunID
+	dsn, err := createRunDatabase(ctx, b.dsn, b.database)
+	if err != nil {
+		return fmt.Errorf("create the database of run %s: %w", options.RunID, err)
+	}
+	log.Printf("Tables of the run in database %s", b.database)
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return err
+	}
+	b.db = db
+
+	var version string
+	err = db.QueryRowContext(ctx, "SELECT VERSION()").Scan(&version)
+	if err != nil {
+		return err
+	}
+	log.Printf("MySQL server %s", version)
+
+	if err := migration.Run(db, "up", migration.Config{Dialect: migration.MySQL}); err != nil {
+		return fmt.Errorf("goose up: %w", err)
+	}
+	return nil
+}
+
+func (b *Backend) Scenarios() []bench.Scenario {
+	return append([]bench.Scenario{
+		{Name: "insert users", Run: b.insertUsers, Op: b.insertUser},
+		{Name: "insert articles", Run: b.insertArticles, Op: b.insertArticle},
+		{Name: "insert articles without references", Run: b.insertArticlesWithoutReferences, Op: b.insertArticleWithoutReferences},
+		{Name: "insert comments", Run: b.insertComments, Op: b.insertComment},
+		{Name: "insert comments without references", Run: b.insertCommentsWithoutReferences, Op: b.insertCommentWithoutReferences},
+		bench.Check("verify row counts", b.verifyRowCounts),
+		bench.Check("verify integrity", b.verifyIntegrity),
+		bench.Check("storage after data load", func(ctx context.Context, errs *bench.Errors) {
+			b.storageReport(ctx, "AFTER DATA LOAD")
+		}),
+		{Name: "select users by id", Run: b.selectFromIdUsers, Op: b.selectUserById},
+		{Name: "select with joins", Run: b.selectWithJoins, Op: b.selectJoined},
+		{Name: "select with filters", Run: b.selectWithFilters, Op: b.selectFiltered},
+		{Name: "select with joins and filters", Run: b.selectWithJoinsAndFilters, Op: b.selectJoinedAndFiltered},
+		{Name: "add nullable column", Run: b.addNullableColumn, Note: onlineDDL},
+		{Name: "add column with default", Run: b.addNullableWithDefault, Note: onlineDDL},
+		{Name: "drop column", Run: b.dropColumn, Note: onlineDDL},
+		bench.Check("storage after DDL", func(ctx context.Context, errs *bench.Errors) {
+			b.storageReport(ctx, "AFTER DDL")
+		}),
+		{Name: "bulk insert articles", Run: b.loadData},
+		{Name: "multiline insert articles", Run: b.multilineInsertArticles},
+		bench.Check("verify row counts", b.verifyRowCounts),
+	}, b.payloadScenarios()...)
+}
+
+// Teardown drops the database of the run, unless it is kept, and closes the
+// connection pool
+func (b *Backend) Teardown() {
+	if b.db == nil {
+		return
+	}
+	if b.keep {
+		log.Printf("Kept database %s", b.database)
+	} else if err := dropDatabase(context.Background(), b.db, b.database); err != nil {
+		log.Printf("drop database %s: %v", b.database, err)
+	}
+
+	if err := b.db.Close(); err != nil {
+		log.Printf("close db: %v", err)
+		return
+	}
+	log.Print("db connection closed")
+}
+
+func (b *Backend) Classify(err error) bench.ErrorClass {
+	return classify(err)
+}
+
+func (b *Backend) Retryable(err error) bool {
+	return retryable(err)
+}
+
+// verifyRowCounts fails the run if a table does not hold the rows that were requested
+func (b *Backend) verifyRowCounts(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	err := b.expectedRows.Verify(func(table string) (int64, error) {
+		var count int64
+		err := b.db.QueryRowContext(ctx, "SELECT count(*) FROM `"+table+"`").Scan(&count)
+		return count, err
+	})
+	if err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+func (b *Backend) insertUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ============")
+	log.Printf("Insert %d users in progress...", b.profile.Users)
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["users"] += int64(b.profile.Users)
+	metrics := bench.RunWorkers(ctx, b.profile.Users, b.poolCount, func(currentPosition int) error {
+		return b.insertUser(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertUser(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO users (id, name, description) VALUES (?, ?, ?)`
+	name := b.data.Text("users.name", currentPosition)
+	descr := b.data.Text("users.description", currentPosition)
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, name, descr)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["users"].Add(currentPosition, name, descr)
+	return nil
+}
+
+func (b *Backend) insertArticles(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ARTICLES ============")
+	log.Printf("Insert %d articles in progress...", b.profile.Articles())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	b.generated["articles"] = b.profile.Articles()
+	metrics := bench.RunWorkers(ctx, b.profile.Articles(), b.poolCount, func(currentPosition int) error {
+		return b.insertArticle(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertArticle(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO articles (id, author_id, title, text) VALUES (?, ?, ?, ?)`
+	title := b.data.Text("articles.title", currentPosition)
+	text := b.data.Text("articles.text", currentPosition)
+
+	// referenced ids wrap around, so benchmarks can insert more rows than were loaded
+	authorId := b.profile.AuthorOf(currentPosition)
+
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, authorId, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["articles"].Add(currentPosition, authorId, title, text)
+	return nil
+}
+
+func (b *Backend) insertArticlesWithoutReferences(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ARTICLES WITHOUT REFERENCES =================")
+	log.Printf("Insert %d articles in progress...", b.profile.Articles())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["articles_simple"] += int64(b.profile.Articles())
+	metrics := bench.RunWorkers(ctx, b.profile.Articles(), b.poolCount, func(currentPosition int) error {
+		return b.insertArticleWithoutReferences(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertArticleWithoutReferences(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO articles_simple (id, author_id, title, text) VALUES (?, ?, ?, ?)`
+	title := b.data.Text("articles.title", currentPosition)
+	text := b.data.Text("articles.text", currentPosition)
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, currentPosition, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["articles_simple"].Add(currentPosition, currentPosition, title, text)
+	return nil
+}
+
+func (b *Backend) insertComments(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT COMMENTS ============")
+	log.Printf("Insert %d comments in progress...", b.profile.Comments())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["comments"] += int64(b.profile.Comments())
+	b.generated["comments"] = b.profile.Comments()
+	metrics := bench.RunWorkers(ctx, b.profile.Comments(), b.poolCount, func(currentPosition int) error {
+		return b.insertComment(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertComment(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO comments (id, author_id, article_id, title, text) VALUES (?, ?, ?, ?, ?)`
+	title := b.data.Text("comments.title", currentPosition)
+	text := b.data.Text("comments.text", currentPosition)
+
+	// referenced ids wrap around, so benchmarks can insert more rows than were loaded
+	authorId := b.profile.CommenterOf(currentPosition)
+	articleId := b.profile.ArticleOf(currentPosition)
+
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, authorId, articleId, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["comments"].Add(currentPosition, authorId, articleId, title, text)
+	return nil
+}
+
+func (b *Backend) insertCommentsWithoutReferences(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT COMMENTS WITHOUT REFERENCES =================")
+	log.Printf("Insert %d comments in progress...", b.profile.Comments())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["comments_simple"] += int64(b.profile.Comments())
+	metrics := bench.RunWorkers(ctx, b.profile.Comments(), b.poolCount, func(currentPosition int) error {
+		return b.insertCommentWithoutReferences(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertCommentWithoutReferences(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO comments_simple (id, author_id, article_id, title, text) VALUES (?, ?, ?, ?, ?)`
+	title := b.data.Text("comments.title", currentPosition)
+	text := b.data.Text("comments.text", currentPosition)
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, currentPosition, currentPosition, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["comments_simple"].Add(currentPosition, currentPosition, currentPosition, title, text)
+	return nil
+}
+
+func (b *Backend) selectFromIdUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT FROM ID =======")
+	log.Printf("Select %d users in progress...", b.profile.Users)
+
+	var selectsPerConnection int = 1000
+
+	metrics := bench.RunLoop(ctx, b.poolCount, selectsPerConnection, func(position int) error {
+		return b.selectUserById(ctx, errs, position)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Average RPS for %d pools = %.0f selects", b.poolCount, metrics.RPS)
+	log.Printf("Latency p50 = %s, p95 = %s, p99 = %s", metrics.P50, metrics.P95, metrics.P99)
+	log.Printf("Select test passed in %s", elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) selectUserById(ctx context.Context, errs *bench.Errors, position int) error {
+	id := b.data.Intn("select users by id", position, b.profile.Users)
+	sqlStatement := `SELECT id, name, description FROM users WHERE id = ?`
+	return errs.Do(ctx, func() error {
+		var user struct {
+			id                int64
+			name, description string
+		}
+		return b.db.QueryRowContext(ctx, sqlStatement, id).Scan(&user.id, &user.name, &user.description)
+	})
+}
+
+func (b *Backend) selectWithJoins(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT ALL WITH JOIN =======")
+	log.Printf("Select rows with join in progress...")
+
+	countRows, err := b.queryCount(ctx, errs, selectWithJoinsQuery)
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Selected all with join %d rows in %s", countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+func (b *Backend) selectWithFilters(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT WITH FILTER =======")
+	log.Printf("Select rows with filter in progress...")
+
+	id := b.data.Intn("select with filters", 0, b.profile.Users+1)
+	countRows, err := b.queryCount(ctx, errs, selectWithFiltersQuery, id)
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Selected with filter (filter id > %d) %d rows in %s", id, countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+func (b *Backend) selectWithJoinsAndFilters(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT ALL WITH JOIN AND FILTERS =======")
+	log.Printf("Select rows with join and filters in progress...")
+
+	id := b.data.Intn("select with joins and filters", 0, b.profile.Comments()+1)
+	countRows, err := b.queryCount(ctx, errs, selectWithJoinsAndFiltersQuery, id)
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Selected (filter id > %d) all with join and filters %d rows in %s", id, countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+// queryCount runs a query and counts the rows it returns
+func (b *Backend) queryCount(ctx context.Context, errs *bench.Errors, query string, args ...interface{}) (int64, error) {
+	var countRows int64
+	err := errs.Do(ctx, func() error {
+		rows, err := b.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		countRows = 0
+		for rows.Next() {
+			countRows++
+		}
+		return rows.Err()
+	})
+	return countRows, err
+}
+
+// selectJoined, selectFiltered and selectJoinedAndFiltered repeat the query of
+// their scenario as a single operation
+func (b *Backend) selectJoined(ctx context.Context, errs *bench.Errors, _ int) error {
+	_, err := b.queryCount(ctx, errs, selectWithJoinsQuery)
+	return err
+}
+
+func (b *Backend) selectFiltered(ctx context.Context, errs *bench.Errors, position int) error {
+	_, err := b.queryCount(ctx, errs, selectWithFiltersQuery, b.data.Intn("select with filters", position, b.profile.Users+1))
+	return err
+}
+
+func (b *Backend) selectJoinedAndFiltered(ctx context.Context, errs *bench.Errors, position int) error {
+	_, err := b.queryCount(ctx, errs, selectWithJoinsAndFiltersQuery, b.data.Intn("select with joins and filters", position, b.profile.Comments()+1))
+	return err
+}
+
+func (b *Backend) addNullableColumn(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= ADD NULLABLE COLUMN =======")
+	log.Printf("Insert nullable column in progress...")
+
+	sqlStatement := `ALTER TABLE users ADD COLUMN nullable_column TEXT`
+	err := errs.Do(ctx, func() error {
+		return b.alterOnline(ctx, sqlStatement)
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted nullable column in %s", elapsed)
+	log.Print("==============================")
+
+	return 0
+}
+
+func (b *Backend) addNullableWithDefault(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= ADD COLUMN WITH DEFAULT =======")
+	log.Printf("Insert new column with default value in progress...")
+
+	// text columns take a default only as an expression
+	sqlStatement := `ALTER TABLE users ADD COLUMN default_column TEXT NOT NULL DEFAULT ('default text in new column')`
+	err := errs.Do(ctx, func() error {
+		return b.alterOnline(ctx, sqlStatement)
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted new column with default value in %s", elapsed)
+	log.Print("==============================")
+
+	return 0
+}
+
+func (b *Backend) dropColumn(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= DROP COLUMN =======")
+	log.Printf("Drop column in progress...")
+
+	sqlStatement := `ALTER TABLE users DROP COLUMN default_column`
+	err := errs.Do(ctx, func() error {
+		return b.alterOnline(ctx, sqlStatement)
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Dropped column in %s", elapsed)
+	log.Print("==============================")
+
+	return 0
+}
+
+// onlineDDL is the note of the DDL scenarios, the statements run without
+// blocking the writes to the table
+const onlineDDL = "ALGORITHM=INSTANT, ALGORITHM=INPLACE with LOCK=NONE if the server rejects it, the log says which"
+
+// alterOnline runs the ALTER TABLE statement with ALGORITHM=INSTANT, which
+// only changes the metadata, servers that reject it for the statement, e.g.
+// MySQL before 8.0.12 or DROP COLUMN before 8.0.29, run it with
+// ALGORITHM=INPLACE and LOCK=NONE. A server that can not run it online
+// either fails the scenario instead of copying the table.
+func (b *Backend) alterOnline(ctx context.Context, statement string) error {
+	_, err := b.db.ExecContext(ctx, statement+", ALGORITHM=INSTANT")
+	if !rejectsAlgorithm(err) {
+		if err == nil {
+			log.Print("Ran with ALGORITHM=INSTANT")
+		}
+		return err
+	}
+
+	log.Printf("The server rejects ALGORITHM=INSTANT: %v", err)
+	_, err = b.db.ExecContext(ctx, statement+", ALGORITHM=INPLACE, LOCK=NONE")
+	if err == nil {
+		log.Print("Ran with ALGORITHM=INPLACE, LOCK=NONE")
+	}
+	return err
+}
+
+func (b *Backend) loadData(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== BULK INSERT ARTICLES ============")
+	log.Printf("Bulk insert %d articles in progress...", b.profile.Articles())
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	var loaded int64
+	err := errs.Do(ctx, func() error {
+		var err error
+		loaded, err = b.loadArticles(ctx)
+		return err
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Bulk inserted %d rows in %s", loaded, elapsed)
+	log.Print("==============================")
+
+	return loaded
+}
+
+// loadFieldEscaper escapes the field values for the default format of LOAD DATA,
+// tab separated fields with backslash escapes
+var loadFieldEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`)
+
+// loadArticles is the MySQL counterpart of COPY: the rows are streamed to
+// LOAD DATA LOCAL INFILE through a reader handler of the driver, the server
+// needs local_infile enabled. A single statement is atomic with InnoDB.
+func (b *Backend) loadArticles(ctx context.Context) (int64, error) {
+	articles := b.profile.Articles()
+	reader, writer := io.Pipe()
+	// closing the reader stops the writer if the statement fails before reading everything
+	defer reader.Close()
+
+	go func() {
+		for n := 0; n < articles; n++ {
+			authorId := b.profile.AuthorOf(n)
+			id := n + articles*2
+			title := b.data.Text("articles.title", id)
+			text := b.data.Text("articles.text", id)
+
+			_, err := fmt.Fprintf(writer, "%d\t%d\t%s\t%s\n", id, authorId, loadFieldEscaper.Replace(title), loadFieldEscaper.Replace(text))
+			if err != nil {
+				return
+			}
+		}
+		writer.Close()
+	}()
+
+	mysql.RegisterReaderHandler("articles", func() io.Reader {
+		return reader
+	})
+	defer mysql.DeregisterReaderHandler("articles")
+
+	res, err := b.db.ExecContext(ctx, "LOAD DATA LOCAL INFILE 'Reader::articles' INTO TABLE articles CHARACTER SET utf8mb4 (id, author_id, title, text)")
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// multilineInsertArticles is the bulk load without LOAD DATA, multi-row
+// INSERTs of multiRowBatch rows in a single transaction
+func (b *Backend) multilineInsertArticles(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== MULTILINE INSERT ARTICLES ============")
+	log.Printf("Multiline insert %d articles in progress...", b.profile.Articles())
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	var countRows int64
+	err := errs.Do(ctx, func() error {
+		var err error
+		countRows, err = b.insertArticlesInBatches(ctx)
+		return err
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Multiline inserted %d rows in %s", countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+func (b *Backend) insertArticlesInBatches(ctx context.Context) (int64, error) {
+	articles := b.profile.Articles()
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var inserted int64
+	for from := 0; from < articles; from += multiRowBatch {
+		to := from + multiRowBatch
+		if to > articles {
+			to = articles
+		}
+
+		var query strings.Builder
+		query.WriteString("INSERT INTO articles (id, author_id, title, text) VALUES ")
+		args := make([]interface{}, 0, (to-from)*4)
+		for n := from; n < to; n++ {
+			if n != from {
+				query.WriteString(", ")
+			}
+			query.WriteString("(?, ?, ?, ?)")
+			id := n + articles*3
+			args = append(args, id, b.profile.AuthorOf(n), b.data.Text("articles.title", id), b.data.Text("articles.text", id))
+		}
+
+		res, err := tx.ExecContext(ctx, query.String(), args...)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += affected
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return inserted, nil
+}
@@ -0,0 +1,95 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"time"
+)
+
+// payloadTable holds the rows of the payload sweep, it is created again for
+// every size, so its size is the footprint of the payloads of a single size
+const payloadTable = "payload_sweep"
+
+// payloadScenarios insert and read texts of the payload sizes, InnoDB keeps
+// long values on overflow pages, they are counted in the data of the table
+func (b *Backend) payloadScenarios() []bench.Scenario {
+	return bench.PayloadSweep(b.payloadSizes, b.preparePayloads, b.dropPayloads, b.insertPayloads, b.selectPayloads)
+}
+
+func (b *Backend) preparePayloads(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+	_, err := b.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+payloadTable+`;
+		CREATE TABLE `+payloadTable+` (id bigint PRIMARY KEY, body longtext NOT NULL)`)
+	if err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+func (b *Backend) dropPayloads(ctx context.Context, errs *bench.Errors) {
+	if _, err := b.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+payloadTable); err != nil && ctx.Err() == nil {
+		log.Printf("drop %s: %v", payloadTable, err)
+	}
+}
+
+func (b *Backend) insertPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "insert payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== INSERT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Insert %d rows with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				body := b.data.Payload(size, position)
+				return errs.Do(ctx, func() error {
+					_, err := b.db.ExecContext(ctx, `INSERT INTO `+payloadTable+` (id, body) VALUES (?, ?)`, position, body)
+					return err
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+		Storage: func(ctx context.Context) (bench.TableSize, error) {
+			tables, err := b.tableSizes(ctx, []string{payloadTable})
+			if err != nil {
+				return bench.TableSize{}, err
+			}
+			if len(tables) == 0 {
+				return bench.TableSize{}, fmt.Errorf("table %s not found", payloadTable)
+			}
+			return tables[0], nil
+		},
+	}
+}
+
+func (b *Backend) selectPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "select payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== SELECT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Select %d rows with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				id := b.data.Intn("select payload", position, rows)
+				return errs.Do(ctx, func() error {
+					var body string
+					return b.db.QueryRowContext(ctx, `SELECT body FROM `+payloadTable+` WHERE id = ?`, id).Scan(&body)
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"github.com/go-sql-driver/mysql"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"time"
+)
+
+// createRunDatabase creates the database the tables of a run are migrated
+// to and returns the DSN of the connections of the run, the user of the DSN
+// needs the CREATE and DROP privileges on the databases of the runs
+func createRunDatabase(ctx context.Context, dsn, database string) (string, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS `"+database+"`"); err != nil {
+		return "", err
+	}
+	return withDatabase(dsn, database)
+}
+
+// withDatabase replaces the database of the DSN
+func withDatabase(dsn, database string) (string, error) {
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	config.DBName = database
+	return config.FormatDSN(), nil
+}
+
+// Keep makes Teardown leave the database of the run for inspection
+func (b *Backend) Keep() {
+	b.keep = true
+}
+
+func dropDatabase(ctx context.Context, db *sql.DB, database string) error {
+	_, err := db.ExecContext(ctx, "DROP DATABASE IF EXISTS `"+database+"`")
+	return err
+}
+
+// Cleanup drops the databases of the runs started before cutoff that were
+// kept or not removed, e.g. after a crash, and returns their names. With
+// dryRun it only lists them.
+func Cleanup(ctx context.Context, dsn string, cutoff time.Time, dryRun bool) ([]string, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `SELECT schema_name FROM information_schema.schemata ORDER BY schema_name`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, database := range bench.Leftovers(names, cutoff) {
+		if !dryRun {
+			if err := dropDatabase(ctx, db, database); err != nil {
+				return dropped, err
+			}
+			log.Printf("Dropped database %s", database)
+		}
+		dropped = append(dropped, database)
+	}
+	return dropped, nil
+}
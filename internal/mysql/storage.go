@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+)
+
+// storageReport logs the size of the tables and their indexes, InnoDB keeps
+// long values off-page within the data of the table, so there is no toast
+func (b *Backend) storageReport(ctx context.Context, title string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	tableSizes, err := b.tableSizes(ctx, tables)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Storage report failed: %v", err)
+		}
+		return
+	}
+	bench.LogStorage(title, tableSizes)
+}
+
+func (b *Backend) tableSizes(ctx context.Context, names []string) ([]bench.TableSize, error) {
+	in, args := inTables(names)
+
+	// information_schema caches the statistics, ANALYZE refreshes them
+	rows, err := b.db.QueryContext(ctx, "ANALYZE TABLE "+strings.Join(names, ", "))
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = b.db.QueryContext(ctx, `SELECT table_name, data_length, index_length, data_length + index_length
+	FROM information_schema.tables
+	WHERE table_schema = DATABASE() AND table_name IN (`+in+`)
+	ORDER BY table_name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tableSizes []bench.TableSize
+	byName := map[string]int{}
+	for rows.Next() {
+		var table bench.TableSize
+		if err := rows.Scan(&table.Name, &table.Heap, &table.Indexes, &table.Total); err != nil {
+			return nil, err
+		}
+		byName[table.Name] = len(tableSizes)
+		tableSizes = append(tableSizes, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// the index statistics need read access to the mysql schema, without it
+	// only the totals are reported
+	indexRows, err := b.db.QueryContext(ctx, `SELECT table_name, index_name, stat_value * @@innodb_page_size
+	FROM mysql.innodb_index_stats
+	WHERE database_name = DATABASE() AND stat_name = 'size' AND table_name IN (`+in+`)
+	ORDER BY table_name, index_name`, args...)
+	if err != nil {
+		log.Printf("Index sizes unavailable: %v", err)
+		return tableSizes, nil
+	}
+	defer indexRows.Close()
+
+	for indexRows.Next() {
+		var tableName string
+		var index bench.IndexSize
+		if err := indexRows.Scan(&tableName, &index.Name, &index.Size); err != nil {
+			return nil, err
+		}
+		if i, ok := byName[tableName]; ok {
+			tableSizes[i].IndexSizes = append(tableSizes[i].IndexSizes, index)
+		}
+	}
+	return tableSizes, indexRows.Err()
+}
+
+// inTables is the placeholder list and the arguments of an IN clause over names
+func inTables(names []string) (string, []interface{}) {
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "), args
+}
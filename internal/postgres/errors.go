@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"github.com/lib/pq"
+	"postgres_performance_test/internal/bench"
+)
+
+// classify maps pq errors to error classes by their SQLSTATE
+func classify(err error) bench.ErrorClass {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23505":
+			return bench.DuplicateKey
+		case pqErr.Code.Class() == "23":
+			return bench.ConstraintViolation
+		case pqErr.Code == "40001", pqErr.Code == "40P01":
+			return bench.SerializationFailure
+		case pqErr.Code == "57014":
+			// query_canceled, raised by statement_timeout
+			return bench.Timeout
+		case pqErr.Code.Class() == "08", pqErr.Code == "57P01", pqErr.Code == "57P02", pqErr.Code == "57P03":
+			return bench.ConnectionReset
+		}
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return bench.ConnectionReset
+	}
+	return bench.ClassifyTransport(err)
+}
+
+// retryable accepts serialization failures, deadlocks, admin shutdowns
+// (failovers) and connection errors
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001" || pqErr.Code == "40P01" || pqErr.Code == "57P01" || pqErr.Code.Class() == "08"
+	}
+	return errors.Is(err, driver.ErrBadConn) || bench.ClassifyTransport(err) == bench.ConnectionReset
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"time"
+)
+
+// Flavor is the database speaking the Postgres wire protocol.
+type Flavor string
+
+const (
+	Vanilla   Flavor = "postgres"
+	Cockroach Flavor = "cockroach"
+	Yugabyte  Flavor = "yugabyte"
+)
+
+// txRetries is how many times a transaction failing with a serialization
+// failure is repeated by the flavors that expect clients to retry
+const txRetries = 10
+
+func ParseFlavor(value string) (Flavor, error) {
+	switch flavor := Flavor(strings.ToLower(value)); flavor {
+	case Vanilla, Cockroach, Yugabyte:
+		return flavor, nil
+	case "", "postgresql":
+		return Vanilla, nil
+	}
+	return "", fmt.Errorf("unknown postgres flavor %q, expected postgres, cockroach or yugabyte", value)
+}
+
+// flavored replaces the scenarios a flavor can not run by their substitutes or
+// marks them unsupported
+func (b *Backend) flavored(scenarios []bench.Scenario) []bench.Scenario {
+	if b.flavor == Vanilla {
+		return scenarios
+	}
+
+	for i, scenario := range scenarios {
+		switch {
+		case strings.HasPrefix(scenario.Name, "storage "):
+			// the size functions do not see the distributed storage
+			scenarios[i] = bench.Unsupported(scenario, fmt.Sprintf("%s does not report table sizes through pg_relation_size", b.flavor))
+		case scenario.Storage != nil:
+			scenario.Storage = nil
+			scenario.Note = fmt.Sprintf("%s does not report table sizes through pg_relation_size", b.flavor)
+			scenarios[i] = scenario
+		case scenario.Name == "bulk insert articles" && b.flavor == Cockroach:
+			scenario.Run = b.bulkInsertInBatches
+			scenario.Note = "COPY substituted by multi-row INSERTs"
+			scenarios[i] = scenario
+		}
+	}
+	return scenarios
+}
+
+// inTransaction runs fn in a transaction. The distributed flavors abort
+// conflicting transactions with 40001 and expect the client to run them
+// again, so for them the whole transaction is repeated up to txRetries times.
+func (b *Backend) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	attempts := 1
+	if b.flavor != Vanilla {
+		attempts += txRetries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			log.Printf("Transaction restarted after serialization failure, attempt %d", attempt+1)
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(time.Duration(attempt) * 10 * time.Millisecond):
+			}
+		}
+
+		err = runTransaction(ctx, b.db, fn)
+		if !isSerializationFailure(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func runTransaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "40001"
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"io"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/fixture"
+	"postgres_performance_test/migration"
+	"strings"
+)
+
+// Import loads the rows of the fixture with COPY in a single transaction,
+// cockroach gets multi-row INSERTs like in the bulk insert. The transaction
+// is not repeated on serialization failures as the rows are read only once.
+func (b *Backend) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	var imported int64
+	checksum := &bench.Checksum{}
+	err := runTransaction(ctx, b.db, func(tx *sql.Tx) error {
+		var err error
+		if b.flavor == Cockroach {
+			imported, err = insertRows(ctx, tx, rows, b.schema, checksum)
+		} else {
+			imported, err = copyRows(ctx, tx, rows, b.schema, checksum)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	b.expectedRows[table] += imported
+	b.checksums.Table(table).Merge(checksum)
+	b.profile = b.profile.Imported(table, imported)
+	return imported, nil
+}
+
+func copyRows(ctx context.Context, tx *sql.Tx, rows *fixture.Reader, schema migration.Schema, checksum *bench.Checksum) (int64, error) {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(rows.Table(), rows.Columns()...))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var copied int64
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		withIDs(schema, row)
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return 0, err
+		}
+		checksum.Add(row...)
+		copied++
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return 0, err
+	}
+	return copied, stmt.Close()
+}
+
+// insertRows inserts the rows with INSERTs of insertBatch rows
+func insertRows(ctx context.Context, tx *sql.Tx, rows *fixture.Reader, schema migration.Schema, checksum *bench.Checksum) (int64, error) {
+	columns := rows.Columns()
+	var inserted int64
+	for done := false; !done; {
+		var query strings.Builder
+		fmt.Fprintf(&query, "INSERT INTO %s (%s) VALUES ", pq.QuoteIdentifier(rows.Table()), strings.Join(columns, ", "))
+		args := make([]interface{}, 0, insertBatch*len(columns))
+		batch := bench.Checksum{}
+		for len(args) < insertBatch*len(columns) {
+			row, err := rows.Next()
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				return 0, err
+			}
+			withIDs(schema, row)
+
+			if len(args) > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("(")
+			for i := range row {
+				if i > 0 {
+					query.WriteString(", ")
+				}
+				fmt.Fprintf(&query, "$%d", len(args)+i+1)
+			}
+			query.WriteString(")")
+			args = append(args, row...)
+			batch.Add(row...)
+		}
+		if len(args) == 0 {
+			break
+		}
+
+		res, err := tx.ExecContext(ctx, query.String(), args...)
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		inserted += affected
+		checksum.Merge(&batch)
+	}
+	return inserted, nil
+}
+
+// withIDs converts the ids of the row, the ints of the reader, to the ids of schema
+func withIDs(schema migration.Schema, row []interface{}) {
+	for i, value := range row {
+		if id, ok := value.(int); ok {
+			row[i] = schema.ID(id)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"time"
+)
+
+// payloadTable holds the articles of the payload sweep, it is created again
+// for every size, so its size is the footprint of the payloads of a single
+// size. The articles table keeps the loaded rows, which would be counted too,
+// and its text may be a varchar(n) of the schema variant.
+const payloadTable = "payload_sweep"
+
+// payloadNote tells in the report why the sweep does not use articles
+const payloadNote = "articles in " + payloadTable + ", a table per size, so the storage is that of the payloads only"
+
+// payloadScenarios insert and read articles with texts of the payload sizes,
+// values over about 2 KB are compressed and moved to the TOAST table
+func (b *Backend) payloadScenarios() []bench.Scenario {
+	return bench.PayloadSweep(b.payloadSizes, b.preparePayloads, b.dropPayloads, b.insertPayloads, b.selectPayloads)
+}
+
+func (b *Backend) preparePayloads(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+	_, err := b.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+payloadTable+`;
+		CREATE TABLE `+payloadTable+` (id bigint PRIMARY KEY, author_id bigint NOT NULL, title text NOT NULL, text text NOT NULL)`)
+	if err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+func (b *Backend) dropPayloads(ctx context.Context, errs *bench.Errors) {
+	if _, err := b.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+payloadTable); err != nil && ctx.Err() == nil {
+		log.Printf("drop %s: %v", payloadTable, err)
+	}
+}
+
+func (b *Backend) insertPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "insert payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== INSERT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Insert %d articles with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				title := b.data.Text("articles.title", position)
+				text := b.data.Payload(size, position)
+				return errs.Do(ctx, func() error {
+					_, err := b.db.ExecContext(ctx, `INSERT INTO `+payloadTable+` (id, author_id, title, text) VALUES ($1, $2, $3, $4)`,
+						position, b.profile.AuthorOf(position), title, text)
+					return err
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+		Note:    payloadNote,
+		Storage: b.tableStorage(payloadTable),
+	}
+}
+
+func (b *Backend) selectPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "select payload " + name,
+		Note: payloadNote,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== SELECT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Select %d articles with %s of text in progress...", rows, name)
+
+			// the text is scanned, so the value is detoasted and sent to the client
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				id := b.data.Intn("select payload", position, rows)
+				return errs.Do(ctx, func() error {
+					var authorId int64
+					var title, text string
+					return b.db.QueryRowContext(ctx, `SELECT author_id, title, text FROM `+payloadTable+` WHERE id = $1`, id).Scan(&authorId, &title, &text)
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"log"
+	"net/url"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"time"
+)
+
+// createRunSchema creates the schema the tables of a run are migrated to, it
+// is the first schema of the search path of the connections of the run
+func createRunSchema(ctx context.Context, dsn, schema string) (string, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `CREATE SCHEMA IF NOT EXISTS `+pq.QuoteIdentifier(schema)); err != nil {
+		return "", err
+	}
+	return withSearchPath(dsn, schema)
+}
+
+// withSearchPath adds the search path to the DSN, pq passes it to the server
+// as a run-time parameter of every connection
+func withSearchPath(dsn, schema string) (string, error) {
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " search_path=" + schema, nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// Keep makes Teardown leave the schema of the run for inspection
+func (b *Backend) Keep() {
+	b.keep = true
+}
+
+func dropSchema(ctx context.Context, db *sql.DB, schema string) error {
+	_, err := db.ExecContext(ctx, `DROP SCHEMA IF EXISTS `+pq.QuoteIdentifier(schema)+` CASCADE`)
+	return err
+}
+
+// Cleanup drops the schemas of the runs started before cutoff that were kept
+// or not removed, e.g. after a crash, and returns their names. With dryRun
+// it only lists them.
+func Cleanup(ctx context.Context, dsn string, cutoff time.Time, dryRun bool) ([]string, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `SELECT nspname FROM pg_namespace ORDER BY nspname`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, schema := range bench.Leftovers(names, cutoff) {
+		if !dryRun {
+			if err := dropSchema(ctx, db, schema); err != nil {
+				return dropped, err
+			}
+			log.Printf("Dropped schema %s", schema)
+		}
+		dropped = append(dropped, schema)
+	}
+	return dropped, nil
+}
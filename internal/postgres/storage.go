@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"log"
+	"postgres_performance_test/internal/bench"
+)
+
+var storageTables = []string{"users", "articles", "articles_simple", "comments", "comments_simple"}
+
+func storageReport(ctx context.Context, db *sql.DB, title string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	tables, err := tableSizes(ctx, db, storageTables)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Storage report failed: %v", err)
+		}
+		return
+	}
+	bench.LogStorage(title, tables)
+}
+
+// tableStorage measures the footprint of table after the scenario that loads it
+func (b *Backend) tableStorage(table string) func(ctx context.Context) (bench.TableSize, error) {
+	return func(ctx context.Context) (bench.TableSize, error) {
+		tables, err := tableSizes(ctx, b.db, []string{table})
+		if err != nil {
+			return bench.TableSize{}, err
+		}
+		if len(tables) == 0 {
+			return bench.TableSize{}, fmt.Errorf("table %s not found", table)
+		}
+		return tables[0], nil
+	}
+}
+
+func tableSizes(ctx context.Context, db *sql.DB, names []string) ([]bench.TableSize, error) {
+	rows, err := db.QueryContext(ctx, `SELECT c.relname,
+		pg_relation_size(c.oid),
+		pg_indexes_size(c.oid),
+		COALESCE(pg_total_relation_size(NULLIF(c.reltoastrelid, 0)), 0),
+		pg_total_relation_size(c.oid)
+	FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = current_schema() AND c.relkind = 'r' AND c.relname = ANY($1)
+	ORDER BY c.relname`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []bench.TableSize
+	byName := map[string]int{}
+	for rows.Next() {
+		var table bench.TableSize
+		if err := rows.Scan(&table.Name, &table.Heap, &table.Indexes, &table.Toast, &table.Total); err != nil {
+			return nil, err
+		}
+		byName[table.Name] = len(tables)
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	indexRows, err := db.QueryContext(ctx, `SELECT t.relname, i.relname, pg_relation_size(i.oid)
+	FROM pg_index x
+	JOIN pg_class i ON i.oid = x.indexrelid
+	JOIN pg_class t ON t.oid = x.indrelid
+	JOIN pg_namespace n ON n.oid = t.relnamespace
+	WHERE n.nspname = current_schema() AND t.relname = ANY($1)
+	ORDER BY t.relname, i.relname`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer indexRows.Close()
+
+	for indexRows.Next() {
+		var tableName string
+		var index bench.IndexSize
+		if err := indexRows.Scan(&tableName, &index.Name, &index.Size); err != nil {
+			return nil, err
+		}
+		if i, ok := byName[tableName]; ok {
+			tables[i].IndexSizes = append(tables[i].IndexSizes, index)
+		}
+	}
+	return tables, indexRows.Err()
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+)
+
+// checksumColumns are the columns hashed per table, in the order the insert
+// scenarios pass them to Checksum.Add
+var checksumColumns = map[string][]string{
+	"users":           {"id", "name", "description"},
+	"articles":        {"id", "author_id", "title", "text"},
+	"articles_simple": {"id", "author_id", "title", "text"},
+	"comments":        {"id", "author_id", "article_id", "title", "text"},
+	"comments_simple": {"id", "author_id", "article_id", "title", "text"},
+}
+
+// verifyIntegrity is enabled by Options.Verify, it fails the run if the loaded
+// data has orphaned references, implausible values or does not match the
+// checksums of the inserted rows
+func (b *Backend) verifyIntegrity(ctx context.Context, errs *bench.Errors) {
+	if !b.verify || ctx.Err() != nil {
+		return
+	}
+
+	log.Print("========== VERIFY INTEGRITY ============")
+
+	err := bench.RunIntegrityChecks(b.integrityChecks(ctx))
+	if err == nil {
+		err = b.checksums.Verify(func(table string) (*bench.Checksum, error) {
+			return b.readChecksum(ctx, table)
+		})
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			errs.Fail(err)
+		}
+		return
+	}
+
+	log.Print("Integrity verified")
+	log.Print("==============================")
+}
+
+func (b *Backend) integrityChecks(ctx context.Context) []bench.IntegrityCheck {
+	var checks []bench.IntegrityCheck
+	add := func(name, query string) {
+		checks = append(checks, bench.IntegrityCheck{Name: name, Count: func() (int64, error) {
+			var count int64
+			err := b.db.QueryRowContext(ctx, query).Scan(&count)
+			return count, err
+		}})
+	}
+	loaded := func(table string) bool {
+		_, ok := b.expectedRows[table]
+		return ok
+	}
+
+	if loaded("users") {
+		add("users with empty name or description",
+			`SELECT count(*) FROM users WHERE name = '' OR description = ''`)
+	}
+	if loaded("articles") {
+		add("articles without author",
+			`SELECT count(*) FROM articles a LEFT JOIN users u ON u.id = a.author_id WHERE u.id IS NULL`)
+		add("articles with empty title or text",
+			`SELECT count(*) FROM articles WHERE title = '' OR text = ''`)
+	}
+	if loaded("articles_simple") {
+		add("articles without references with unexpected author",
+			`SELECT count(*) FROM articles_simple WHERE author_id <> id`)
+	}
+	if loaded("comments") {
+		add("comments without author",
+			`SELECT count(*) FROM comments c LEFT JOIN users u ON u.id = c.author_id WHERE u.id IS NULL`)
+		add("comments without article",
+			`SELECT count(*) FROM comments c LEFT JOIN articles a ON a.id = c.article_id WHERE a.id IS NULL`)
+		add("comments with empty title or text",
+			`SELECT count(*) FROM comments WHERE title = '' OR text = ''`)
+	}
+	if loaded("comments_simple") {
+		add("comments without references with unexpected references",
+			`SELECT count(*) FROM comments_simple WHERE author_id <> id OR article_id <> id`)
+	}
+
+	return append(checks, b.titleChecks(ctx)...)
+}
+
+// readChecksum computes the checksum of the rows stored in table
+func (b *Backend) readChecksum(ctx context.Context, table string) (*bench.Checksum, error) {
+	columns := checksumColumns[table]
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = pq.QuoteIdentifier(column)
+	}
+
+	rows, err := b.db.QueryContext(ctx, "SELECT "+strings.Join(quoted, ", ")+" FROM "+pq.QuoteIdentifier(table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	raw := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	fields := make([]interface{}, len(columns))
+
+	checksum := &bench.Checksum{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, value := range raw {
+			// NULL hashes like the nil argument of the insert
+			if value == nil {
+				fields[i] = nil
+				continue
+			}
+			fields[i] = string(value)
+		}
+		checksum.Add(fields...)
+	}
+	return checksum, rows.Err()
+}
+
+// titleChecks compare the titles of a sample of the generated rows with the
+// generator
+func (b *Backend) titleChecks(ctx context.Context) []bench.IntegrityCheck {
+	var checks []bench.IntegrityCheck
+	for _, table := range []string{"articles", "comments"} {
+		table := table
+		if b.generated[table] == 0 {
+			continue
+		}
+		checks = append(checks, bench.TitleCheck(table, b.generated[table], b.data, func(position int) (string, bool, error) {
+			var title string
+			err := b.db.QueryRowContext(ctx, "SELECT title FROM "+table+" WHERE id = $1", b.id(position)).Scan(&title)
+			if err == sql.ErrNoRows {
+				return "", false, nil
+			}
+			return title, err == nil, err
+		}))
+	}
+	return checks
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"context"
+	goredis "github.com/redis/go-redis/v9"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cleanup removes the keys of the runs started before cutoff that were kept
+// or not removed, e.g. after a crash, and returns the prefixes of the runs.
+// With dryRun it only lists them.
+func Cleanup(ctx context.Context, addr string, cutoff time.Time, dryRun bool) ([]string, error) {
+	client := goredis.NewClient(&goredis.Options{Addr: addr})
+	defer client.Close()
+
+	runs := map[string]bool{}
+	err := scan(ctx, client, bench.RunPrefix+"*", func(batch []string) error {
+		for _, key := range batch {
+			if i := strings.Index(key, ":"); i > 0 {
+				runs[key[:i]] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range runs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var removed []string
+	for _, name := range bench.Leftovers(names, cutoff) {
+		if !dryRun {
+			n, err := removeKeys(ctx, client, name+":")
+			if err != nil {
+				return removed, err
+			}
+			log.Printf("Removed %d keys %s:*", n, name)
+		}
+		removed = append(removed, name)
+	}
+	return removed, nil
+}
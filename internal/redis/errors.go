@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"errors"
+	goredis "github.com/redis/go-redis/v9"
+	"postgres_performance_test/internal/bench"
+	"strings"
+)
+
+// classify maps redis errors to error classes by the prefix of the error
+// reply, redis has no error codes
+func classify(err error) bench.ErrorClass {
+	var redisErr goredis.Error
+	if errors.As(err, &redisErr) && err != goredis.Nil {
+		switch {
+		case strings.HasPrefix(redisErr.Error(), "EXECABORT"), strings.HasPrefix(redisErr.Error(), "TRYAGAIN"):
+			return bench.SerializationFailure
+		case strings.HasPrefix(redisErr.Error(), "LOADING"), strings.HasPrefix(redisErr.Error(), "READONLY"),
+			strings.HasPrefix(redisErr.Error(), "MASTERDOWN"):
+			// the server is starting or failing over
+			return bench.ConnectionReset
+		}
+	}
+	if errors.Is(err, goredis.ErrClosed) {
+		return bench.ConnectionReset
+	}
+	return bench.ClassifyTransport(err)
+}
+
+// retryable accepts failovers, aborted transactions and connection errors
+func retryable(err error) bool {
+	class := classify(err)
+	return class == bench.SerializationFailure || class == bench.ConnectionReset
+}
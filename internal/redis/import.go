@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"io"
+	"postgres_performance_test/internal/fixture"
+)
+
+// Import sets the hashes of the rows of the fixture in pipelines of
+// pipelineBatch rows like the bulk insert, redis keeps no comments
+func (b *Backend) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	if _, ok := keys[table]; !ok {
+		return 0, fmt.Errorf("redis keeps no %s", table)
+	}
+
+	var imported int64
+	for done := false; !done && ctx.Err() == nil; {
+		var batch [][]interface{}
+		for len(batch) < pipelineBatch {
+			row, err := rows.Next()
+			if err == io.EOF {
+				done = true
+				break
+			}
+			if err != nil {
+				return imported, err
+			}
+			batch = append(batch, row)
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		_, err := b.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, row := range batch {
+				if table == "users" {
+					pipe.HSet(ctx, b.userKey(row[0].(int)), "name", row[1], "description", row[2])
+				} else {
+					b.addArticle(ctx, pipe, row[0].(int), row[1].(int), row[2].(string), row[3].(string))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return imported, err
+		}
+
+		for _, row := range batch {
+			b.checksums[table].Add(row...)
+		}
+		imported += int64(len(batch))
+		b.expectedRows[table] += int64(len(batch))
+	}
+
+	b.profile = b.profile.Imported(table, imported)
+	return imported, nil
+}
@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"context"
+	goredis "github.com/redis/go-redis/v9"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// payloadKey is the prefix of the strings of the payload sweep after the
+// prefix of the run, they are removed again for every size
+const payloadKey = "payload:"
+
+// payloadScenarios set and get strings of the payload sizes, the storage is
+// the MEMORY USAGE of the keys
+func (b *Backend) payloadScenarios() []bench.Scenario {
+	scenarios := bench.PayloadSweep(b.payloadSizes, b.preparePayloads, b.dropPayloads, b.insertPayloads, b.selectPayloads)
+	if b.server != nil {
+		for i := range scenarios {
+			if scenarios[i].Storage != nil {
+				scenarios[i].Storage = nil
+				scenarios[i].Note = "the in-process stand-in does not report memory usage"
+			}
+		}
+	}
+	return scenarios
+}
+
+func (b *Backend) removePayloads(ctx context.Context) error {
+	return b.scan(ctx, payloadKey+"*", func(batch []string) error {
+		return b.client.Unlink(ctx, batch...).Err()
+	})
+}
+
+func (b *Backend) preparePayloads(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := b.removePayloads(ctx); err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+func (b *Backend) dropPayloads(ctx context.Context, errs *bench.Errors) {
+	if err := b.removePayloads(ctx); err != nil && ctx.Err() == nil {
+		log.Printf("remove %s%s*: %v", b.prefix, payloadKey, err)
+	}
+}
+
+func (b *Backend) insertPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "insert payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== INSERT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Set %d keys with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				body := b.data.Payload(size, position)
+				return errs.Do(ctx, func() error {
+					return b.client.Set(ctx, b.prefix+payloadKey+strconv.Itoa(position), body, 0).Err()
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+		Storage: func(ctx context.Context) (bench.TableSize, error) {
+			var used int64
+			err := b.scan(ctx, payloadKey+"*", func(batch []string) error {
+				cmds, err := b.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+					for _, key := range batch {
+						pipe.MemoryUsage(ctx, key)
+					}
+					return nil
+				})
+				for _, cmd := range cmds {
+					used += cmd.(*goredis.IntCmd).Val()
+				}
+				return err
+			})
+			return bench.TableSize{Name: "payload keys", Heap: used, Total: used}, err
+		},
+	}
+}
+
+func (b *Backend) selectPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "select payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== SELECT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Get %d keys with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				id := b.data.Intn("select payload", position, rows)
+				return errs.Do(ctx, func() error {
+					return b.client.Get(ctx, b.prefix+payloadKey+strconv.Itoa(id)).Err()
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+	}
+}
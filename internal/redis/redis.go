@@ -0,0 +1,461 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAddr is the redis-server started by docker-compose.yml
+const DefaultAddr = "localhost:6379"
+
+// InProcess runs the scenarios against miniredis, a stand-in started inside
+// the process, it needs no server but tells nothing about redis performance
+const InProcess = "miniredis"
+
+// keys are the key prefixes of the rows of the tables after the prefix of the
+// run, every user and article is a hash and every author has the sorted set of
+// the ids of their articles
+var keys = map[string]string{
+	"users":    "user:",
+	"articles": "article:",
+}
+
+// authorArticlesKey is the prefix of the sorted sets of the authors, adding an
+// id again leaves them unchanged, so the pipelines can be retried
+const authorArticlesKey = "author-articles:"
+
+// pipelineBatch is the number of commands sent at once by the pipelined bulk load
+const pipelineBatch = 1000
+
+// Backend runs the scenarios against Redis, it holds the state of a single run.
+type Backend struct {
+	addr         string
+	server       *miniredis.Miniredis
+	client       *goredis.Client
+	prefix       string
+	keep         bool
+	profile      bench.Profile
+	poolCount    int
+	verify       bool
+	expectedRows bench.ExpectedRows
+	checksums    bench.Checksums
+	data         *datagen.Generator
+	payloadSizes []int
+}
+
+// New creates the backend for the server at addr, or InProcess
+func New(addr string) *Backend {
+	return &Backend{addr: addr}
+}
+
+func (b *Backend) Name() string {
+	return "REDIS"
+}
+
+// Client is the client of the run, for custom scenarios
+func (b *Backend) Client() *goredis.Client {
+	return b.client
+}
+
+func (b *Backend) Setup(ctx context.Context, options bench.Options, errs *bench.Errors) error {
+	b.profile = options.Dataset()
+	b.poolCount = options.Workers
+	b.verify = options.Verify
+	b.expectedRows = bench.ExpectedRows{}
+	b.checksums = bench.Checksums{}
+	b.data = options.Generator()
+	if err := b.data.CheckNullable(nil); err != nil {
+		return err
+	}
+	b.payloadSizes = options.PayloadSizes
+	for table := range keys {
+		b.checksums.Table(table)
+	}
+	b.client = nil
+	b.keep = false
+	if options.RunID == "" {
+		return fmt.Errorf("no run id, the keys of the run are prefixed with it")
+	}
+	b.prefix = bench.RunPrefix + options.RunID + ":"
+
+	addr := b.addr
+	if addr == InProcess {
+		server, err := miniredis.Run()
+		if err != nil {
+			return err
+		}
+		b.server = server
+		addr = server.Addr()
+		log.Printf("Started in-process redis stand-in on %s", addr)
+	}
+
+	b.client = goredis.NewClient(&goredis.Options{Addr: addr, PoolSize: b.poolCount})
+	if err := b.client.Ping(ctx).Err(); err != nil {
+		return err
+	}
+	log.Printf("Keys of the run prefixed with %s", b.prefix)
+	return nil
+}
+
+func (b *Backend) Scenarios() []bench.Scenario {
+	storage := bench.Check("storage after data load", b.storageReport)
+	if b.server != nil {
+		storage = bench.Unsupported(storage, "the in-process stand-in does not report memory usage")
+	}
+
+	return append([]bench.Scenario{
+		{Name: "insert users", Run: b.insertUsers, Op: b.insertUser},
+		{Name: "insert articles", Run: b.insertArticles, Op: b.insertArticle},
+		bench.Check("verify row counts", b.verifyRowCounts),
+		bench.Check("verify integrity", b.verifyIntegrity),
+		storage,
+		{Name: "select users by id", Run: b.selectFromIdUsers, Op: b.selectUserById},
+		{Name: "select articles by author", Run: b.selectArticlesByAuthor, Op: b.selectAuthorArticles},
+		{Name: "bulk insert articles", Run: b.bulkInsert},
+		bench.Check("verify row counts", b.verifyRowCounts),
+	}, b.payloadScenarios()...)
+}
+
+// Teardown removes the keys of the run, unless they are kept, and disconnects
+func (b *Backend) Teardown() {
+	if b.client != nil {
+		if b.keep {
+			log.Printf("Kept keys %s*", b.prefix)
+		} else {
+			log.Print("Remove benchmark keys...")
+			removed, err := removeKeys(context.Background(), b.client, b.prefix)
+			if err != nil {
+				log.Printf("Removing keys failed: %v", err)
+			} else {
+				log.Printf("Removed %d keys", removed)
+			}
+		}
+
+		if err := b.client.Close(); err != nil {
+			log.Printf("Closing the redis connection failed: %v", err)
+		} else {
+			log.Print("redis connection closed")
+		}
+	}
+	if b.server != nil {
+		b.server.Close()
+		b.server = nil
+	}
+}
+
+// Keep makes Teardown leave the keys of the run for inspection
+func (b *Backend) Keep() {
+	b.keep = true
+}
+
+func (b *Backend) Classify(err error) bench.ErrorClass {
+	return classify(err)
+}
+
+func (b *Backend) Retryable(err error) bool {
+	return retryable(err)
+}
+
+// removeKeys unlinks the keys starting with prefix
+func removeKeys(ctx context.Context, client *goredis.Client, prefix string) (int64, error) {
+	var removed int64
+	err := scan(ctx, client, prefix+"*", func(batch []string) error {
+		n, err := client.Unlink(ctx, batch...).Result()
+		removed += n
+		return err
+	})
+	return removed, err
+}
+
+// scan calls fn with the batches of the keys of the run matching pattern
+func (b *Backend) scan(ctx context.Context, pattern string, fn func(batch []string) error) error {
+	return scan(ctx, b.client, b.prefix+pattern, fn)
+}
+
+// scan calls fn with the batches of the keys matching pattern
+func scan(ctx context.Context, client *goredis.Client, pattern string, fn func(batch []string) error) error {
+	var cursor uint64
+	for {
+		batch, next, err := client.Scan(ctx, cursor, pattern, pipelineBatch).Result()
+		if err != nil {
+			return err
+		}
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// countKeys counts the rows of table, there is no count(*) in redis
+func (b *Backend) countKeys(ctx context.Context, table string) (int64, error) {
+	var count int64
+	err := b.scan(ctx, keys[table]+"*", func(batch []string) error {
+		count += int64(len(batch))
+		return nil
+	})
+	return count, err
+}
+
+// verifyRowCounts fails the run if a table does not hold the rows that were requested
+func (b *Backend) verifyRowCounts(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	err := b.expectedRows.Verify(func(table string) (int64, error) {
+		return b.countKeys(ctx, table)
+	})
+	if err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+// storageReport logs the memory used by the server, redis does not report it per key prefix
+func (b *Backend) storageReport(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	info, err := b.client.Info(ctx, "memory").Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Storage report failed: %v", err)
+		}
+		return
+	}
+
+	var used int64
+	for _, line := range strings.Split(info, "\r\n") {
+		if strings.HasPrefix(line, "used_memory:") {
+			used, _ = strconv.ParseInt(strings.TrimPrefix(line, "used_memory:"), 10, 64)
+		}
+	}
+	bench.LogStorage("AFTER DATA LOAD", []bench.TableSize{{Name: "used memory", Heap: used, Total: used}})
+}
+
+func (b *Backend) insertUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ============")
+	log.Printf("Insert %d users in progress...", b.profile.Users)
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["users"] += int64(b.profile.Users)
+	metrics := bench.RunWorkers(ctx, b.profile.Users, b.poolCount, func(currentPosition int) error {
+		return b.insertUser(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertUser(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	name := b.data.Text("users.name", currentPosition)
+	descr := b.data.Text("users.description", currentPosition)
+	err := errs.Do(ctx, func() error {
+		return b.client.HSet(ctx, b.userKey(currentPosition), "name", name, "description", descr).Err()
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["users"].Add(currentPosition, name, descr)
+	return nil
+}
+
+func (b *Backend) insertArticles(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ARTICLES ============")
+	log.Printf("Insert %d articles in progress...", b.profile.Articles())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	metrics := bench.RunWorkers(ctx, b.profile.Articles(), b.poolCount, func(currentPosition int) error {
+		return b.insertArticle(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+// insertArticle stores the article and adds it to the set of its author in a
+// MULTI/EXEC transaction, so no article is missing from the sets
+func (b *Backend) insertArticle(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	title := b.data.Text("articles.title", currentPosition)
+	text := b.data.Text("articles.text", currentPosition)
+
+	// referenced ids wrap around, so benchmarks can insert more rows than were loaded
+	authorId := b.profile.AuthorOf(currentPosition)
+
+	err := errs.Do(ctx, func() error {
+		_, err := b.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			b.addArticle(ctx, pipe, currentPosition, authorId, title, text)
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["articles"].Add(currentPosition, authorId, title, text)
+	return nil
+}
+
+// addArticle sets the hash of the article and adds its id to the set of the
+// author, both by key, so a retried pipeline writes the same again
+func (b *Backend) addArticle(ctx context.Context, pipe goredis.Pipeliner, id, authorId int, title, text string) {
+	pipe.HSet(ctx, b.articleKey(id), "author_id", authorId, "title", title, "text", text)
+	pipe.ZAdd(ctx, b.authorArticlesKey(authorId), goredis.Z{Score: float64(id), Member: id})
+}
+
+func (b *Backend) selectFromIdUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT FROM ID =======")
+	log.Printf("Select %d users in progress...", b.profile.Users)
+
+	var selectsPerConnection int = 1000
+
+	metrics := bench.RunLoop(ctx, b.poolCount, selectsPerConnection, func(position int) error {
+		return b.selectUserById(ctx, errs, position)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Average RPS for %d pools = %.0f selects", b.poolCount, metrics.RPS)
+	log.Printf("Latency p50 = %s, p95 = %s, p99 = %s", metrics.P50, metrics.P95, metrics.P99)
+	log.Printf("Select test passed in %s", elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) selectUserById(ctx context.Context, errs *bench.Errors, position int) error {
+	id := b.data.Intn("select users by id", position, b.profile.Users)
+	return errs.Do(ctx, func() error {
+		return b.client.HGetAll(ctx, b.userKey(id)).Err()
+	})
+}
+
+func (b *Backend) selectArticlesByAuthor(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT ARTICLES BY AUTHOR =======")
+	log.Printf("Select articles of random authors in progress...")
+
+	var selectsPerConnection int = 100
+
+	metrics := bench.RunLoop(ctx, b.poolCount, selectsPerConnection, func(position int) error {
+		return b.selectAuthorArticles(ctx, errs, position)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Average RPS for %d pools = %.0f selects", b.poolCount, metrics.RPS)
+	log.Printf("Latency p50 = %s, p95 = %s, p99 = %s", metrics.P50, metrics.P95, metrics.P99)
+	log.Printf("Select test passed in %s", elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+// selectAuthorArticles is the redis counterpart of the join of users and
+// articles: the first ids from the set of the author and their hashes in a pipeline
+func (b *Backend) selectAuthorArticles(ctx context.Context, errs *bench.Errors, position int) error {
+	authorId := b.data.Intn("select articles by author", position, b.profile.Users)
+	return errs.Do(ctx, func() error {
+		ids, err := b.client.ZRange(ctx, b.authorArticlesKey(authorId), 0, 49).Result()
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		_, err = b.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.HGetAll(ctx, b.userKey(authorId))
+			for _, id := range ids {
+				pipe.HGetAll(ctx, b.tableKey("articles")+id)
+			}
+			return nil
+		})
+		return err
+	})
+}
+
+func (b *Backend) bulkInsert(ctx context.Context, errs *bench.Errors) int64 {
+	articles := b.profile.Articles()
+	start := time.Now()
+	log.Print("========== BULK INSERT ARTICLES ============")
+	log.Printf("Bulk insert %d articles in pipelines of %d in progress...", articles, pipelineBatch)
+
+	b.expectedRows["articles"] += int64(articles)
+	var inserted int64
+	for from := 0; from < articles && ctx.Err() == nil; from += pipelineBatch {
+		to := from + pipelineBatch
+		if to > articles {
+			to = articles
+		}
+
+		err := errs.Do(ctx, func() error {
+			_, err := b.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+				for n := from; n < to; n++ {
+					id := n + articles*2
+					b.addArticle(ctx, pipe, id, b.profile.AuthorOf(n), b.data.Text("articles.title", id), b.data.Text("articles.text", id))
+				}
+				return nil
+			})
+			return err
+		})
+		if err != nil {
+			continue
+		}
+		inserted += int64(to - from)
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Bulk inserted %d rows in %s", inserted, elapsed)
+	log.Print("==============================")
+
+	return inserted
+}
+
+// tableKey is the prefix of the keys of the rows of table in the run
+func (b *Backend) tableKey(table string) string {
+	return b.prefix + keys[table]
+}
+
+func (b *Backend) userKey(id int) string {
+	return b.tableKey("users") + strconv.Itoa(id)
+}
+
+func (b *Backend) articleKey(id int) string {
+	return b.tableKey("articles") + strconv.Itoa(id)
+}
+
+func (b *Backend) authorArticlesKey(authorId int) string {
+	return b.prefix + authorArticlesKey + strconv.Itoa(authorId)
+}
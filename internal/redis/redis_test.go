@@ -0,0 +1,59 @@
+package redis
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunsKeepTheirKeysApart(t *testing.T) {
+	server := miniredis.RunT(t)
+	kept, removed := bench.NewRunID(), bench.NewRunID()+"x"
+	for id, cleanup := range map[string]bench.Cleanup{kept: bench.CleanupNever, removed: bench.CleanupAlways} {
+		options := bench.Options{Rows: 20, Workers: 2, Verify: true, RunID: id, Cleanup: cleanup, ErrorPolicy: bench.ErrorPolicy{OnError: bench.Abort}}
+		if report := bench.NewRunner(New(server.Addr()), options).Run(context.Background()); report.Err != nil {
+			t.Fatalf("run %s failed: %v", id, report.Err)
+		}
+	}
+
+	for _, key := range server.Keys() {
+		if !strings.HasPrefix(key, bench.RunPrefix+kept+":") {
+			t.Fatalf("unexpected key %s after the runs", key)
+		}
+	}
+	if len(server.Keys()) == 0 {
+		t.Fatal("the keys of the kept run were removed")
+	}
+
+	runs, err := Cleanup(context.Background(), server.Addr(), time.Now().Add(time.Minute), false)
+	if err != nil || len(runs) != 1 || runs[0] != bench.RunPrefix+kept {
+		t.Fatalf("cleanup removed %v: %v", runs, err)
+	}
+	if keys := server.Keys(); len(keys) != 0 {
+		t.Fatalf("%d keys left after the cleanup", len(keys))
+	}
+}
+
+func TestRetriedArticleInsertsAddTheArticleOnce(t *testing.T) {
+	server := miniredis.RunT(t)
+	b := New(server.Addr())
+	ctx := context.Background()
+	errs := bench.NewErrors(bench.ErrorPolicy{OnError: bench.Abort}, b.Classify, b.Retryable, func() {})
+	if err := b.Setup(ctx, bench.Options{Rows: 10, Workers: 1, RunID: bench.NewRunID()}, errs); err != nil {
+		t.Fatal(err)
+	}
+	defer b.Teardown()
+
+	// a retry repeats the whole transaction of the article
+	for i := 0; i < 2; i++ {
+		if err := b.insertArticle(ctx, errs, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := b.client.ZCard(ctx, b.authorArticlesKey(b.profile.AuthorOf(0))).Val(); n != 1 {
+		t.Fatalf("the article is %d times in the set of its author", n)
+	}
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	goredis "github.com/redis/go-redis/v9"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+)
+
+// checksumFields are the hash fields hashed per table after the id, in the
+// order the insert scenarios pass them to Checksum.Add
+var checksumFields = map[string][]string{
+	"users":    {"name", "description"},
+	"articles": {"author_id", "title", "text"},
+}
+
+// verifyIntegrity is enabled by Options.Verify, it fails the run if an article
+// is missing from the set of its author or the stored hashes do not match the
+// checksums of the inserted rows
+func (b *Backend) verifyIntegrity(ctx context.Context, errs *bench.Errors) {
+	if !b.verify || ctx.Err() != nil {
+		return
+	}
+
+	log.Print("========== VERIFY INTEGRITY ============")
+
+	err := bench.RunIntegrityChecks([]bench.IntegrityCheck{
+		{Name: "articles missing from the sets of their authors", Count: func() (int64, error) {
+			return b.countUnlistedArticles(ctx)
+		}},
+	})
+	if err == nil {
+		err = b.checksums.Verify(func(table string) (*bench.Checksum, error) {
+			return b.readChecksum(ctx, table)
+		})
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			errs.Fail(err)
+		}
+		return
+	}
+
+	log.Print("Integrity verified")
+	log.Print("==============================")
+}
+
+// countUnlistedArticles compares the number of articles with the total size
+// of the sets of the authors
+func (b *Backend) countUnlistedArticles(ctx context.Context) (int64, error) {
+	articles, err := b.countKeys(ctx, "articles")
+	if err != nil {
+		return 0, err
+	}
+
+	var listed int64
+	err = b.scan(ctx, authorArticlesKey+"*", func(batch []string) error {
+		cmds, err := b.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.ZCard(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			listed += cmd.(*goredis.IntCmd).Val()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if listed > articles {
+		return 0, nil
+	}
+	return articles - listed, nil
+}
+
+// readChecksum computes the checksum of the hashes stored for table
+func (b *Backend) readChecksum(ctx context.Context, table string) (*bench.Checksum, error) {
+	fields := checksumFields[table]
+	checksum := &bench.Checksum{}
+	err := b.scan(ctx, keys[table]+"*", func(batch []string) error {
+		cmds, err := b.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, key := range batch {
+				pipe.HMGet(ctx, key, fields...)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for i, cmd := range cmds {
+			row := []interface{}{strings.TrimPrefix(batch[i], b.tableKey(table))}
+			for _, value := range cmd.(*goredis.SliceCmd).Val() {
+				row = append(row, fmt.Sprint(value))
+			}
+			checksum.Add(row...)
+		}
+		return nil
+	})
+	return checksum, err
+}
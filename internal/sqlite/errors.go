@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"errors"
+	"github.com/mattn/go-sqlite3"
+	"postgres_performance_test/internal/bench"
+)
+
+// classify maps sqlite3 errors to error classes by their result codes
+func classify(err error) bench.ErrorClass {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch {
+		case sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique, sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey:
+			return bench.DuplicateKey
+		case sqliteErr.Code == sqlite3.ErrConstraint:
+			return bench.ConstraintViolation
+		case sqliteErr.Code == sqlite3.ErrBusy, sqliteErr.Code == sqlite3.ErrLocked:
+			// another connection holds the write lock for longer than the busy timeout
+			return bench.SerializationFailure
+		}
+	}
+	return bench.ClassifyTransport(err)
+}
+
+// retryable accepts lock contention, there are no network errors with SQLite
+func retryable(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
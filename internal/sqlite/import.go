@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/fixture"
+	"strings"
+)
+
+// Import inserts the rows of the fixture with a prepared statement in a single
+// transaction like the bulk insert
+func (b *Backend) Import(ctx context.Context, table string, rows *fixture.Reader) (int64, error) {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	columns := rows.Columns()
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`, table, strings.Join(columns, ", "), placeholders))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	checksum := &bench.Checksum{}
+	var imported int64
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			return 0, err
+		}
+		checksum.Add(row...)
+		imported++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	b.expectedRows[table] += imported
+	b.checksums.Table(table).Merge(checksum)
+	b.profile = b.profile.Imported(table, imported)
+	return imported, nil
+}
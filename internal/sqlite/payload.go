@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+	"time"
+)
+
+// payloadTable holds the rows of the payload sweep, it is created again for
+// every size, so the pages it takes are the footprint of a single size
+const payloadTable = "payload_sweep"
+
+// payloadScenarios insert and read texts of the payload sizes, values that do
+// not fit in a page spill to overflow pages
+func (b *Backend) payloadScenarios() []bench.Scenario {
+	return bench.PayloadSweep(b.payloadSizes, b.preparePayloads, b.dropPayloads, b.insertPayloads, b.selectPayloads)
+}
+
+func (b *Backend) preparePayloads(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+	_, err := b.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+payloadTable+`;
+		CREATE TABLE `+payloadTable+` (id integer PRIMARY KEY, body text NOT NULL)`)
+	if err != nil {
+		if ctx.Err() == nil {
+			errs.Fail(err)
+		}
+		return
+	}
+
+	// the file is shared by all tables, the table takes the pages used since now
+	b.payloadBase, err = b.usedBytes(ctx)
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Storage before payload failed: %v", err)
+	}
+}
+
+func (b *Backend) dropPayloads(ctx context.Context, errs *bench.Errors) {
+	if _, err := b.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+payloadTable); err != nil && ctx.Err() == nil {
+		log.Printf("drop %s: %v", payloadTable, err)
+	}
+}
+
+// usedBytes is the size of the pages of the database that are not free
+func (b *Backend) usedBytes(ctx context.Context) (int64, error) {
+	var pageCount, freePages, pageSize int64
+	err := b.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount)
+	if err == nil {
+		err = b.db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&freePages)
+	}
+	if err == nil {
+		err = b.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize)
+	}
+	return (pageCount - freePages) * pageSize, err
+}
+
+func (b *Backend) insertPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "insert payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== INSERT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Insert %d rows with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				body := b.data.Payload(size, position)
+				return errs.Do(ctx, func() error {
+					_, err := b.db.ExecContext(ctx, `INSERT INTO `+payloadTable+` (id, body) VALUES (?, ?)`, position, body)
+					return err
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+		Storage: func(ctx context.Context) (bench.TableSize, error) {
+			used, err := b.usedBytes(ctx)
+			size := used - b.payloadBase
+			return bench.TableSize{Name: payloadTable, Heap: size, Total: size}, err
+		},
+	}
+}
+
+func (b *Backend) selectPayloads(size int) bench.Scenario {
+	name := bench.FormatBytes(int64(size))
+	return bench.Scenario{
+		Name: "select payload " + name,
+		Run: func(ctx context.Context, errs *bench.Errors) int64 {
+			start := time.Now()
+			rows := bench.PayloadRows(b.profile.Articles(), size)
+			log.Printf("========== SELECT PAYLOAD %s ============", strings.ToUpper(name))
+			log.Printf("Select %d rows with %s of text in progress...", rows, name)
+
+			metrics := bench.RunWorkers(ctx, rows, b.poolCount, func(position int) error {
+				id := b.data.Intn("select payload", position, rows)
+				return errs.Do(ctx, func() error {
+					var body string
+					return b.db.QueryRowContext(ctx, `SELECT body FROM `+payloadTable+` WHERE id = ?`, id).Scan(&body)
+				})
+			})
+
+			bench.LogPayloadMetrics(metrics, size, time.Since(start))
+			return metrics.Done
+		},
+	}
+}
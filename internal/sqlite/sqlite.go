@@ -0,0 +1,706 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/mattn/go-sqlite3"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/migration"
+	"strings"
+	"time"
+)
+
+var tables = []string{"users", "articles", "articles_simple", "comments", "comments_simple"}
+
+const selectWithJoinsQuery = `SELECT *
+		 FROM users
+         JOIN articles ON articles.author_id = users.id
+		 JOIN comments ON comments.author_id = users.id
+		 LIMIT 50 OFFSET 1;
+         `
+
+const selectWithFiltersQuery = `SELECT *
+		 FROM users
+         WHERE id > ?
+		 LIMIT 50 OFFSET 1;
+         `
+
+const selectWithJoinsAndFiltersQuery = `SELECT *
+		 FROM users
+         JOIN articles ON articles.author_id = users.id
+		 JOIN comments ON comments.author_id = users.id
+		 WHERE comments.id > ?
+		 LIMIT 50 OFFSET 1;
+         `
+
+// DefaultPath is the database file, created in the working directory
+const DefaultPath = "bench.db"
+
+// Memory keeps the database in memory, it is gone when the run ends
+const Memory = ":memory:"
+
+// Journal is the journal mode of the database file.
+type Journal string
+
+const (
+	// WAL lets readers run concurrently with the single writer
+	WAL Journal = "wal"
+	// Rollback is the classic rollback journal, readers and the writer block each other
+	Rollback Journal = "delete"
+)
+
+func ParseJournal(value string) (Journal, error) {
+	switch journal := Journal(strings.ToLower(value)); journal {
+	case WAL, Rollback:
+		return journal, nil
+	case "rollback":
+		return Rollback, nil
+	}
+	return "", fmt.Errorf("unknown journal mode %q, expected wal or rollback", value)
+}
+
+// Backend runs the scenarios against SQLite, it holds the state of a single run.
+type Backend struct {
+	path         string
+	journal      Journal
+	db           *sql.DB
+	profile      bench.Profile
+	poolCount    int
+	verify       bool
+	data         *datagen.Generator
+	expectedRows bench.ExpectedRows
+	checksums    bench.Checksums
+	// generated are the rows per table the insert scenarios generated at the
+	// positions from 0, their titles are verified
+	generated    map[string]int
+	payloadSizes []int
+	// payloadBase is the size of the database before the payloads were inserted
+	payloadBase int64
+}
+
+// New creates the backend for the database file at path, or Memory
+func New(path string, journal Journal) *Backend {
+	return &Backend{path: path, journal: journal}
+}
+
+func (b *Backend) Name() string {
+	return "SQLITE"
+}
+
+// DB is the connection pool of the run, for custom scenarios
+func (b *Backend) DB() *sql.DB {
+	return b.db
+}
+
+// dsn enables the foreign keys, which SQLite does not enforce by default, and
+// makes writers wait for the lock instead of failing at once
+func (b *Backend) dsn() string {
+	if b.path == Memory {
+		return "file::memory:?_foreign_keys=on"
+	}
+	return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=10000&_txlock=immediate&_journal_mode=%s", b.path, b.journal)
+}
+
+func (b *Backend) Setup(ctx context.Context, options bench.Options, errs *bench.Errors) error {
+	// SQLite has no uuid type, the variants are compared on postgres. It
+	// ignores the length of varchar, the generator cuts the texts to it.
+	schema := options.Schema
+	schema.Varchar = 0
+	if !schema.IsDefault() {
+		return fmt.Errorf("%s has no schema variants but varchar(n), can not create %s", b.Name(), options.Schema)
+	}
+	b.profile = options.Dataset()
+	b.poolCount = options.Workers
+	b.verify = options.Verify
+	b.data = options.Generator()
+	if err := b.data.CheckNullable(nil); err != nil {
+		return err
+	}
+	b.payloadSizes = options.PayloadSizes
+	b.expectedRows = bench.ExpectedRows{}
+	b.generated = map[string]int{}
+	b.checksums = bench.Checksums{}
+	for _, table := range tables {
+		b.checksums.Table(table)
+	}
+
+	db, err := sql.Open("sqlite3", b.dsn())
+	if err != nil {
+		return err
+	}
+	b.db = db
+
+	if b.path == Memory {
+		// every connection would open a database of its own
+		db.SetMaxOpenConns(1)
+	}
+
+	var journal string
+	err = db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journal)
+	if err != nil {
+		return err
+	}
+	log.Printf("SQLite database %s, %s journal, sqlite %s", b.path, journal, sqliteVersion())
+
+	if err := migration.Run(db, "up", migration.Config{Dialect: migration.SQLite, Schema: options.Schema}); err != nil {
+		return fmt.Errorf("goose up: %w", err)
+	}
+	return nil
+}
+
+func sqliteVersion() string {
+	version, _, _ := sqlite3.Version()
+	return version
+}
+
+func (b *Backend) Scenarios() []bench.Scenario {
+	return append([]bench.Scenario{
+		{Name: "insert users", Run: b.insertUsers, Op: b.insertUser},
+		{Name: "insert articles", Run: b.insertArticles, Op: b.insertArticle},
+		{Name: "insert articles without references", Run: b.insertArticlesWithoutReferences, Op: b.insertArticleWithoutReferences},
+		{Name: "insert comments", Run: b.insertComments, Op: b.insertComment},
+		{Name: "insert comments without references", Run: b.insertCommentsWithoutReferences, Op: b.insertCommentWithoutReferences},
+		bench.Check("verify row counts", b.verifyRowCounts),
+		bench.Check("verify integrity", b.verifyIntegrity),
+		bench.Check("storage after data load", func(ctx context.Context, errs *bench.Errors) {
+			b.storageReport(ctx, "AFTER DATA LOAD")
+		}),
+		{Name: "select users by id", Run: b.selectFromIdUsers, Op: b.selectUserById},
+		{Name: "select with joins", Run: b.selectWithJoins, Op: b.selectJoined},
+		{Name: "select with filters", Run: b.selectWithFilters, Op: b.selectFiltered},
+		{Name: "select with joins and filters", Run: b.selectWithJoinsAndFilters, Op: b.selectJoinedAndFiltered},
+		{Name: "add nullable column", Run: b.addNullableColumn},
+		{Name: "add column with default", Run: b.addNullableWithDefault},
+		{Name: "drop column", Run: b.dropColumn},
+		bench.Check("storage after DDL", func(ctx context.Context, errs *bench.Errors) {
+			b.storageReport(ctx, "AFTER DDL")
+		}),
+		{Name: "bulk insert articles", Run: b.bulkInsert},
+		bench.Check("verify row counts", b.verifyRowCounts),
+	}, b.payloadScenarios()...)
+}
+
+// Teardown drops the tables and closes the database, the file itself is kept
+func (b *Backend) Teardown() {
+	if b.db == nil {
+		return
+	}
+
+	log.Print("Reset all migrations...")
+	if err := migration.Run(b.db, "reset", migration.Config{Dialect: migration.SQLite}); err != nil {
+		log.Printf("goose reset: %v", err)
+	}
+	if _, err := b.db.Exec(`DROP TABLE IF EXISTS ` + payloadTable); err != nil {
+		log.Printf("drop %s: %v", payloadTable, err)
+	}
+
+	if err := b.db.Close(); err != nil {
+		log.Printf("close db: %v", err)
+		return
+	}
+	log.Print("db connection closed")
+}
+
+func (b *Backend) Classify(err error) bench.ErrorClass {
+	return classify(err)
+}
+
+func (b *Backend) Retryable(err error) bool {
+	return retryable(err)
+}
+
+// verifyRowCounts fails the run if a table does not hold the rows that were requested
+func (b *Backend) verifyRowCounts(ctx context.Context, errs *bench.Errors) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	err := b.expectedRows.Verify(func(table string) (int64, error) {
+		var count int64
+		err := b.db.QueryRowContext(ctx, `SELECT count(*) FROM "`+table+`"`).Scan(&count)
+		return count, err
+	})
+	if err != nil && ctx.Err() == nil {
+		errs.Fail(err)
+	}
+}
+
+// storageReport logs the size of the database, SQLite keeps all tables and
+// indexes in a single file
+func (b *Backend) storageReport(ctx context.Context, title string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	var pageCount, pageSize int64
+	err := b.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount)
+	if err == nil {
+		err = b.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Storage report failed: %v", err)
+		}
+		return
+	}
+
+	size := pageCount * pageSize
+	bench.LogStorage(title, []bench.TableSize{{Name: "database", Heap: size, Total: size}})
+}
+
+func (b *Backend) insertUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ============")
+	log.Printf("Insert %d users in progress...", b.profile.Users)
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["users"] += int64(b.profile.Users)
+	metrics := bench.RunWorkers(ctx, b.profile.Users, b.poolCount, func(currentPosition int) error {
+		return b.insertUser(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertUser(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO users (id, name, description) VALUES (?, ?, ?)`
+	name := b.data.Text("users.name", currentPosition)
+	descr := b.data.Text("users.description", currentPosition)
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, name, descr)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["users"].Add(currentPosition, name, descr)
+	return nil
+}
+
+func (b *Backend) insertArticles(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ARTICLES ============")
+	log.Printf("Insert %d articles in progress...", b.profile.Articles())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	b.generated["articles"] = b.profile.Articles()
+	metrics := bench.RunWorkers(ctx, b.profile.Articles(), b.poolCount, func(currentPosition int) error {
+		return b.insertArticle(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertArticle(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO articles (id, author_id, title, text) VALUES (?, ?, ?, ?)`
+	title := b.data.Text("articles.title", currentPosition)
+	text := b.data.Text("articles.text", currentPosition)
+
+	// referenced ids wrap around, so benchmarks can insert more rows than were loaded
+	authorId := b.profile.AuthorOf(currentPosition)
+
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, authorId, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["articles"].Add(currentPosition, authorId, title, text)
+	return nil
+}
+
+func (b *Backend) insertArticlesWithoutReferences(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT ARTICLES WITHOUT REFERENCES =================")
+	log.Printf("Insert %d articles in progress...", b.profile.Articles())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["articles_simple"] += int64(b.profile.Articles())
+	metrics := bench.RunWorkers(ctx, b.profile.Articles(), b.poolCount, func(currentPosition int) error {
+		return b.insertArticleWithoutReferences(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertArticleWithoutReferences(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO articles_simple (id, author_id, title, text) VALUES (?, ?, ?, ?)`
+	title := b.data.Text("articles.title", currentPosition)
+	text := b.data.Text("articles.text", currentPosition)
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, currentPosition, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["articles_simple"].Add(currentPosition, currentPosition, title, text)
+	return nil
+}
+
+func (b *Backend) insertComments(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT COMMENTS ============")
+	log.Printf("Insert %d comments in progress...", b.profile.Comments())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["comments"] += int64(b.profile.Comments())
+	b.generated["comments"] = b.profile.Comments()
+	metrics := bench.RunWorkers(ctx, b.profile.Comments(), b.poolCount, func(currentPosition int) error {
+		return b.insertComment(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertComment(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO comments (id, author_id, article_id, title, text) VALUES (?, ?, ?, ?, ?)`
+	title := b.data.Text("comments.title", currentPosition)
+	text := b.data.Text("comments.text", currentPosition)
+
+	// referenced ids wrap around, so benchmarks can insert more rows than were loaded
+	authorId := b.profile.CommenterOf(currentPosition)
+	articleId := b.profile.ArticleOf(currentPosition)
+
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, authorId, articleId, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["comments"].Add(currentPosition, authorId, articleId, title, text)
+	return nil
+}
+
+func (b *Backend) insertCommentsWithoutReferences(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== INSERT COMMENTS WITHOUT REFERENCES =================")
+	log.Printf("Insert %d comments in progress...", b.profile.Comments())
+	log.Printf("Use connection pool size = %d", b.poolCount)
+
+	b.expectedRows["comments_simple"] += int64(b.profile.Comments())
+	metrics := bench.RunWorkers(ctx, b.profile.Comments(), b.poolCount, func(currentPosition int) error {
+		return b.insertCommentWithoutReferences(ctx, errs, currentPosition)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted %d rows in %s", metrics.Done, elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) insertCommentWithoutReferences(ctx context.Context, errs *bench.Errors, currentPosition int) error {
+	sqlStatement := `INSERT INTO comments_simple (id, author_id, article_id, title, text) VALUES (?, ?, ?, ?, ?)`
+	title := b.data.Text("comments.title", currentPosition)
+	text := b.data.Text("comments.text", currentPosition)
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement, currentPosition, currentPosition, currentPosition, title, text)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	b.checksums["comments_simple"].Add(currentPosition, currentPosition, currentPosition, title, text)
+	return nil
+}
+
+func (b *Backend) selectFromIdUsers(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT FROM ID =======")
+	log.Printf("Select %d users in progress...", b.profile.Users)
+
+	var selectsPerConnection int = 1000
+
+	metrics := bench.RunLoop(ctx, b.poolCount, selectsPerConnection, func(position int) error {
+		return b.selectUserById(ctx, errs, position)
+	})
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Average RPS for %d pools = %.0f selects", b.poolCount, metrics.RPS)
+	log.Printf("Latency p50 = %s, p95 = %s, p99 = %s", metrics.P50, metrics.P95, metrics.P99)
+	log.Printf("Select test passed in %s", elapsed)
+	log.Print("==============================")
+
+	return metrics.Done
+}
+
+func (b *Backend) selectUserById(ctx context.Context, errs *bench.Errors, position int) error {
+	id := b.data.Intn("select users by id", position, b.profile.Users)
+	sqlStatement := `SELECT id, name, description FROM users WHERE id = ?`
+	return errs.Do(ctx, func() error {
+		var user struct {
+			id                int64
+			name, description string
+		}
+		return b.db.QueryRowContext(ctx, sqlStatement, id).Scan(&user.id, &user.name, &user.description)
+	})
+}
+
+func (b *Backend) selectWithJoins(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT ALL WITH JOIN =======")
+	log.Printf("Select rows with join in progress...")
+
+	countRows, err := b.queryCount(ctx, errs, selectWithJoinsQuery)
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Selected all with join %d rows in %s", countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+func (b *Backend) selectWithFilters(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT WITH FILTER =======")
+	log.Printf("Select rows with filter in progress...")
+
+	id := b.data.Intn("select with filters", 0, b.profile.Users+1)
+	countRows, err := b.queryCount(ctx, errs, selectWithFiltersQuery, id)
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Selected with filter (filter id > %d) %d rows in %s", id, countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+func (b *Backend) selectWithJoinsAndFilters(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= SELECT ALL WITH JOIN AND FILTERS =======")
+	log.Printf("Select rows with join and filters in progress...")
+
+	id := b.data.Intn("select with joins and filters", 0, b.profile.Comments()+1)
+	countRows, err := b.queryCount(ctx, errs, selectWithJoinsAndFiltersQuery, id)
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Selected (filter id > %d) all with join and filters %d rows in %s", id, countRows, elapsed)
+	log.Print("==============================")
+
+	return countRows
+}
+
+// queryCount runs a query and counts the rows it returns, SQLite does not
+// report them for a SELECT run by Exec
+func (b *Backend) queryCount(ctx context.Context, errs *bench.Errors, query string, args ...interface{}) (int64, error) {
+	var countRows int64
+	err := errs.Do(ctx, func() error {
+		rows, err := b.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		countRows = 0
+		for rows.Next() {
+			countRows++
+		}
+		return rows.Err()
+	})
+	return countRows, err
+}
+
+// selectJoined, selectFiltered and selectJoinedAndFiltered repeat the query of
+// their scenario as a single operation
+func (b *Backend) selectJoined(ctx context.Context, errs *bench.Errors, _ int) error {
+	_, err := b.queryCount(ctx, errs, selectWithJoinsQuery)
+	return err
+}
+
+func (b *Backend) selectFiltered(ctx context.Context, errs *bench.Errors, position int) error {
+	_, err := b.queryCount(ctx, errs, selectWithFiltersQuery, b.data.Intn("select with filters", position, b.profile.Users+1))
+	return err
+}
+
+func (b *Backend) selectJoinedAndFiltered(ctx context.Context, errs *bench.Errors, position int) error {
+	_, err := b.queryCount(ctx, errs, selectWithJoinsAndFiltersQuery, b.data.Intn("select with joins and filters", position, b.profile.Comments()+1))
+	return err
+}
+
+func (b *Backend) addNullableColumn(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= ADD NULLABLE COLUMN =======")
+	log.Printf("Insert nullable column in progress...")
+
+	sqlStatement := `ALTER TABLE users ADD COLUMN nullable_column TEXT`
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement)
+		return err
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted nullable column in %s", elapsed)
+	log.Print("==============================")
+
+	return 0
+}
+
+func (b *Backend) addNullableWithDefault(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= ADD COLUMN WITH DEFAULT =======")
+	log.Printf("Insert new column with default value in progress...")
+
+	sqlStatement := `ALTER TABLE users ADD COLUMN default_column TEXT NOT NULL DEFAULT 'default text in new column'`
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement)
+		return err
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Inserted new column with default value in %s", elapsed)
+	log.Print("==============================")
+
+	return 0
+}
+
+func (b *Backend) dropColumn(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("======= DROP COLUMN =======")
+	log.Printf("Drop column in progress...")
+
+	sqlStatement := `ALTER TABLE users DROP COLUMN default_column`
+	err := errs.Do(ctx, func() error {
+		_, err := b.db.ExecContext(ctx, sqlStatement)
+		return err
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Dropped column in %s", elapsed)
+	log.Print("==============================")
+
+	return 0
+}
+
+func (b *Backend) bulkInsert(ctx context.Context, errs *bench.Errors) int64 {
+	start := time.Now()
+	log.Print("========== BULK INSERT ARTICLES ============")
+	log.Printf("Bulk insert %d articles in progress...", b.profile.Articles())
+
+	b.expectedRows["articles"] += int64(b.profile.Articles())
+	var inserted int64
+	err := errs.Do(ctx, func() error {
+		var err error
+		inserted, err = b.insertArticlesInTransaction(ctx)
+		return err
+	})
+	if err != nil {
+		return 0
+	}
+
+	t := time.Now()
+	elapsed := t.Sub(start)
+
+	log.Printf("Bulk inserted %d rows in %s", inserted, elapsed)
+	log.Print("==============================")
+
+	return inserted
+}
+
+// insertArticlesInTransaction is the SQLite counterpart of COPY: a prepared
+// statement run for every row in a single transaction, which is rolled back
+// if anything fails
+func (b *Backend) insertArticlesInTransaction(ctx context.Context) (int64, error) {
+	articles := b.profile.Articles()
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO articles (id, author_id, title, text) VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var inserted int64
+	for n := 0; n < articles; n++ {
+		authorId := b.profile.AuthorOf(n)
+		id := n + articles*2
+		title := b.data.Text("articles.title", id)
+		text := b.data.Text("articles.text", id)
+
+		_, err := stmt.ExecContext(ctx, id, authorId, title, text)
+		if err != nil {
+			return 0, err
+		}
+		inserted++
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return inserted, nil
+}
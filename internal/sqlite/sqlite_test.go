@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/migration"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentRunners(t *testing.T) {
+	reports := make([]*bench.Report, 2)
+	var wg sync.WaitGroup
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			options := bench.Options{Rows: 50, Workers: 2, Verify: true, PayloadSizes: []int{100}, ErrorPolicy: bench.ErrorPolicy{OnError: bench.Abort}}
+			reports[i] = bench.NewRunner(New(Memory, WAL), options).Run(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for i, report := range reports {
+		if report.Partial || report.Err != nil || len(report.Results) == 0 {
+			t.Fatalf("run %d failed: %v", i, report.Err)
+		}
+	}
+}
+
+func TestPayloadSweepOnlyWithSizes(t *testing.T) {
+	for _, sizes := range [][]int{nil, {100, 2 << 10}} {
+		report := bench.NewRunner(New(Memory, WAL), bench.Options{Rows: 20, Workers: 1, PayloadSizes: sizes}).Run(context.Background())
+		if report.Err != nil {
+			t.Fatal(report.Err)
+		}
+		payloads := 0
+		for _, result := range report.Results {
+			if strings.Contains(result.Name, "payload") {
+				payloads++
+			}
+		}
+		// an insert and a read per size
+		if payloads != 2*len(sizes) {
+			t.Fatalf("%d payload scenarios with sizes %v", payloads, sizes)
+		}
+	}
+}
+
+func TestNullableFieldsNeedNullableColumns(t *testing.T) {
+	data, err := datagen.New(0, map[string]datagen.Spec{"articles.text": {Type: "text", Nullable: 0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := bench.NewRunner(New(Memory, WAL), bench.Options{Rows: 10, Workers: 1, Data: data}).Run(context.Background())
+	if report.Err == nil || !strings.Contains(report.Err.Error(), "articles.text") {
+		t.Fatalf("expected an error for the nullable articles.text, got %v", report.Err)
+	}
+}
+
+func TestVerifyFindsOtherTitles(t *testing.T) {
+	for _, tamper := range []bool{false, true} {
+		backend := New(Memory, WAL)
+		var scenarios []bench.Scenario
+		for _, scenario := range backend.Scenarios() {
+			if scenario.Name == "verify integrity" && tamper {
+				scenarios = append(scenarios, bench.Check("tamper a title", func(ctx context.Context, errs *bench.Errors) {
+					if _, err := backend.DB().ExecContext(ctx, "UPDATE articles SET title = 'tampered' WHERE id = 0"); err != nil {
+						errs.Fail(err)
+					}
+				}))
+			}
+			scenarios = append(scenarios, scenario)
+			if scenario.Name == "verify integrity" {
+				break
+			}
+		}
+
+		options := bench.Options{Rows: 20, Workers: 2, Verify: true, ErrorPolicy: bench.ErrorPolicy{OnError: bench.Abort}}
+		report := bench.NewRunner(backend, options, scenarios...).Run(context.Background())
+		if !tamper && report.Err != nil {
+			t.Fatal(report.Err)
+		}
+		if tamper && (report.Err == nil || !strings.Contains(report.Err.Error(), "articles with other titles than generated: 1 rows")) {
+			t.Fatalf("the tampered title was not found: %v", report.Err)
+		}
+	}
+}
+
+func TestVarcharVariantGetsTextsOfTheColumnSize(t *testing.T) {
+	schema, err := migration.ParseSchema("varchar(32)")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SQLite does not enforce the length, the texts are measured after the run
+	backend := New(Memory, WAL)
+	var longest int64
+	scenarios := append(backend.Scenarios(), bench.Check("longest text", func(ctx context.Context, errs *bench.Errors) {
+		for _, column := range []string{"users.name", "users.description", "articles.title", "articles.text", "comments.title", "comments.text"} {
+			table, name, _ := strings.Cut(column, ".")
+			var length int64
+			if err := backend.DB().QueryRowContext(ctx, "SELECT max(length("+name+")) FROM "+table).Scan(&length); err != nil {
+				errs.Fail(err)
+				return
+			}
+			if length > longest {
+				longest = length
+			}
+		}
+	}))
+
+	options := bench.Options{Rows: 50, Workers: 2, Verify: true, Schema: schema, ErrorPolicy: bench.ErrorPolicy{OnError: bench.Abort}}
+	report := bench.NewRunner(backend, options, scenarios...).Run(context.Background())
+	if report.Err != nil || report.Partial {
+		t.Fatalf("the varchar(32) run failed: %v", report.Err)
+	}
+	if longest == 0 || longest > 32 {
+		t.Fatalf("the longest text has %d characters in varchar(32) columns", longest)
+	}
+
+	schema.IDs = migration.UUIDIDs
+	if report := bench.NewRunner(New(Memory, WAL), bench.Options{Rows: 10, Schema: schema}).Run(context.Background()); report.Err == nil {
+		t.Fatalf("sqlite ran the %s variant", schema)
+	}
+}
@@ -0,0 +1,114 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"postgres_performance_test/internal/bench"
+	"strings"
+)
+
+// checksumColumns are the columns hashed per table, in the order the insert
+// scenarios pass them to Checksum.Add
+var checksumColumns = map[string][]string{
+	"users":           {"id", "name", "description"},
+	"articles":        {"id", "author_id", "title", "text"},
+	"articles_simple": {"id", "author_id", "title", "text"},
+	"comments":        {"id", "author_id", "article_id", "title", "text"},
+	"comments_simple": {"id", "author_id", "article_id", "title", "text"},
+}
+
+// verifyIntegrity is enabled by Options.Verify, it fails the run if
+// PRAGMA foreign_key_check finds orphaned references or the stored rows do not
+// match the checksums of the inserted rows
+func (b *Backend) verifyIntegrity(ctx context.Context, errs *bench.Errors) {
+	if !b.verify || ctx.Err() != nil {
+		return
+	}
+
+	log.Print("========== VERIFY INTEGRITY ============")
+
+	err := bench.RunIntegrityChecks(append([]bench.IntegrityCheck{
+		{Name: "rows violating foreign keys", Count: func() (int64, error) {
+			return b.countForeignKeyViolations(ctx)
+		}},
+	}, b.titleChecks(ctx)...))
+	if err == nil {
+		err = b.checksums.Verify(func(table string) (*bench.Checksum, error) {
+			return b.readChecksum(ctx, table)
+		})
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			errs.Fail(err)
+		}
+		return
+	}
+
+	log.Print("Integrity verified")
+	log.Print("==============================")
+}
+
+func (b *Backend) countForeignKeyViolations(ctx context.Context) (int64, error) {
+	rows, err := b.db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var violations int64
+	for rows.Next() {
+		violations++
+	}
+	return violations, rows.Err()
+}
+
+// readChecksum computes the checksum of the rows stored in table
+func (b *Backend) readChecksum(ctx context.Context, table string) (*bench.Checksum, error) {
+	columns := checksumColumns[table]
+	rows, err := b.db.QueryContext(ctx, "SELECT "+strings.Join(columns, ", ")+` FROM "`+table+`"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	raw := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	fields := make([]interface{}, len(columns))
+
+	checksum := &bench.Checksum{}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, value := range raw {
+			fields[i] = string(value)
+		}
+		checksum.Add(fields...)
+	}
+	return checksum, rows.Err()
+}
+
+// titleChecks compare the titles of a sample of the generated rows with the
+// generator
+func (b *Backend) titleChecks(ctx context.Context) []bench.IntegrityCheck {
+	var checks []bench.IntegrityCheck
+	for _, table := range []string{"articles", "comments"} {
+		table := table
+		if b.generated[table] == 0 {
+			continue
+		}
+		checks = append(checks, bench.TitleCheck(table, b.generated[table], b.data, func(position int) (string, bool, error) {
+			var title string
+			err := b.db.QueryRowContext(ctx, "SELECT title FROM "+table+" WHERE id = ?", position).Scan(&title)
+			if err == sql.ErrNoRows {
+				return "", false, nil
+			}
+			return title, err == nil, err
+		}))
+	}
+	return checks
+}
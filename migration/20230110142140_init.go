@@ -2,6 +2,7 @@ package migration
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/pressly/goose/v3"
 )
 
@@ -10,13 +11,11 @@ func init() {
 }
 
 func upInit(tx *sql.Tx) error {
-	query := `create table users (
-	id          bigint       not null primary key,
-	name          TEXT NOT NULL,
-    description   TEXT NOT NULL);
-
-	CREATE INDEX users_id_index
-	  ON users (id ASC);`
+	query := fmt.Sprintf(`create table users (
+	id          %s       not null primary key,
+	name          %s NOT NULL,
+    description   %s%s);`, schema.idType("users"), schema.textType(), schema.textType(), schema.notNull("users.description")) +
+		schema.idIndex("users_id_index", "users")
 	_, err := tx.Exec(query)
 	if err != nil {
 		return err
@@ -2,7 +2,9 @@ package migration
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/pressly/goose/v3"
+	"strings"
 )
 
 func init() {
@@ -10,17 +12,32 @@ func init() {
 }
 
 func upAddArticles(tx *sql.Tx) error {
-	query := `create table articles (
-	id          serial       not null primary key,
-	author_id   bigint references public.users (id),
+	query := fmt.Sprintf(`create table articles (
+	id          %s       not null primary key,
+	author_id   %s%s,
+	title          %s NOT NULL,
+    text   %s%s);`, schema.idType("articles"), schema.referenceType(), schema.references("users"), schema.textType(), schema.textType(), schema.notNull("articles.text")) +
+		schema.idIndex("article_id_index", "articles") + `
+
+	CREATE INDEX author_id_index
+		  ON articles (author_id ASC);`
+	if dialect == MySQL {
+		query = `create table articles (
+	id          bigint       not null auto_increment primary key,
+	author_id   bigint,
 	title          TEXT NOT NULL,
-    text   TEXT NOT NULL);
+    text   TEXT NOT NULL,
+	foreign key (author_id) references users (id));
 
 	CREATE INDEX article_id_index
 		  ON articles (id ASC);
 
 	CREATE INDEX author_id_index
 		  ON articles (author_id ASC);`
+	}
+	if dialect == Cockroach {
+		query = strings.Replace(query, "serial       not null", "bigint       not null default unique_rowid()", 1)
+	}
 	_, err := tx.Exec(query)
 	if err != nil {
 		return err
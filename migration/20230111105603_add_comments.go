@@ -2,6 +2,7 @@ package migration
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/pressly/goose/v3"
 )
 
@@ -10,12 +11,27 @@ func init() {
 }
 
 func upAddComments(tx *sql.Tx) error {
-	query := `create table comments (
+	query := fmt.Sprintf(`create table comments (
+	id          %s       not null primary key,
+	author_id   %s%s,
+	article_id   %s%s,
+	title          %s NOT NULL,
+    text   %s%s);`, schema.idType("comments"), schema.referenceType(), schema.references("users"),
+		schema.referenceType(), schema.references("articles"), schema.textType(), schema.textType(), schema.notNull("comments.text")) +
+		schema.idIndex("comments_id_index", "comments") + `
+	CREATE INDEX author_id_comments_index
+		  ON comments (author_id ASC);
+	CREATE INDEX article_id_comments_index
+		  ON comments (article_id ASC);`
+	if dialect == MySQL {
+		query = `create table comments (
 	id          bigint       not null primary key,
-	author_id   bigint references public.users (id),
-	article_id   bigint references public.articles (id),
+	author_id   bigint,
+	article_id   bigint,
 	title          TEXT NOT NULL,
-    text   TEXT NOT NULL);
+    text   TEXT NOT NULL,
+	foreign key (author_id) references users (id),
+	foreign key (article_id) references articles (id));
 
 	CREATE INDEX comments_id_index
 		  ON comments (id ASC);
@@ -23,6 +39,7 @@ func upAddComments(tx *sql.Tx) error {
 		  ON comments (author_id ASC);
 	CREATE INDEX article_id_comments_index
 		  ON comments (article_id ASC);`
+	}
 	_, err := tx.Exec(query)
 	if err != nil {
 		return err
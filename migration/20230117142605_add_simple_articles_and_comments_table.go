@@ -2,7 +2,9 @@ package migration
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/pressly/goose/v3"
+	"strings"
 )
 
 func init() {
@@ -10,18 +12,25 @@ func init() {
 }
 
 func upAddSimpleArticlesAndCommentsTable(tx *sql.Tx) error {
-	query := `create table articles_simple (
-	id          serial       not null primary key,
-	author_id   bigint,
-	title          TEXT NOT NULL,
-    text   TEXT NOT NULL);
+	query := fmt.Sprintf(`create table articles_simple (
+	id          %s       not null primary key,
+	author_id   %s,
+	title          %s NOT NULL,
+    text   %s%s);
 
 create table comments_simple (
-	id          bigint       not null primary key,
-	author_id   bigint,
-	article_id   bigint,
-	title          TEXT NOT NULL,
-    text   TEXT NOT NULL);`
+	id          %s       not null primary key,
+	author_id   %s,
+	article_id   %s,
+	title          %s NOT NULL,
+    text   %s%s);`, schema.idType("articles"), schema.referenceType(), schema.textType(), schema.textType(), schema.notNull("articles.text"),
+		schema.idType("comments"), schema.referenceType(), schema.referenceType(), schema.textType(), schema.textType(), schema.notNull("comments.text"))
+	if dialect == MySQL {
+		query = strings.Replace(query, "serial       not null", "bigint       not null auto_increment", 1)
+	}
+	if dialect == Cockroach {
+		query = strings.Replace(query, "serial       not null", "bigint       not null default unique_rowid()", 1)
+	}
 	_, err := tx.Exec(query)
 	if err != nil {
 		return err
@@ -0,0 +1,24 @@
+package migration
+
+// MySQL is the dialect of the mysql backend, it ignores inline references and
+// its serial is unsigned, so the tables with foreign keys get their own DDL
+const MySQL = "mysql"
+
+// Cockroach has no sequence behind serial, the ids of the tables without a
+// given id are generated by unique_rowid()
+const Cockroach = "cockroach"
+
+// SQLite is the dialect of the sqlite backend, it takes the postgres DDL
+const SQLite = "sqlite3"
+
+// dialect is the dialect of the running migrations, it is only set by Run
+var dialect = "postgres"
+
+// gooseDialect is the dialect goose keeps its version table in
+func gooseDialect(d string) string {
+	switch d {
+	case MySQL, SQLite:
+		return d
+	}
+	return "postgres"
+}
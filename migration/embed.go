@@ -0,0 +1,15 @@
+package migration
+
+import (
+	"embed"
+)
+
+// Dir is the directory of the SQL migrations in FS, the Go migrations are
+// registered by the init functions of this package
+const Dir = "sql"
+
+// FS holds the SQL migrations, e.g. sql/20230201120000_add_tags.sql, so the
+// binary does not depend on the working directory
+//
+//go:embed sql
+var FS embed.FS
@@ -0,0 +1,30 @@
+package migration
+
+import (
+	"github.com/pressly/goose/v3"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestMigrationsOutsideTheModule(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	goose.SetBaseFS(FS)
+	defer goose.SetBaseFS(nil)
+
+	migrations, err := goose.CollectMigrations(Dir, 0, math.MaxInt64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 4 || migrations[0].Version != 20230110142140 {
+		t.Fatalf("unexpected migrations %v", migrations)
+	}
+}
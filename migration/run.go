@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"database/sql"
+	"github.com/pressly/goose/v3"
+	"sync"
+)
+
+// Config selects the migrations of a run.
+type Config struct {
+	// Dialect is the DDL of the tables: postgres, cockroach, yugabyte, mysql
+	// or sqlite3
+	Dialect string
+	Schema  Schema
+	// Dir is the directory of the migrations on disk, the migrations in FS
+	// are used if it is empty
+	Dir string
+}
+
+// mx serializes the runs of the migrations: goose keeps its dialect and file
+// system process-wide and the registered migrations can not be given the
+// config of a run
+var mx sync.Mutex
+
+// Run runs the goose command, e.g. up or reset, with the migrations of
+// config. The runs of a process take turns, so concurrent backends migrate
+// their own schema.
+func Run(db *sql.DB, command string, config Config) error {
+	mx.Lock()
+	defer mx.Unlock()
+
+	if err := goose.SetDialect(gooseDialect(config.Dialect)); err != nil {
+		return err
+	}
+	dir := Dir
+	goose.SetBaseFS(FS)
+	if config.Dir != "" {
+		dir = config.Dir
+		goose.SetBaseFS(nil)
+	}
+
+	dialect, schema = config.Dialect, config.Schema
+	defer func() {
+		dialect, schema = "postgres", Schema{}
+	}()
+	return goose.Run(command, db, dir)
+}
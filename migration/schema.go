@@ -0,0 +1,204 @@
+package migration
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSchema is the name of the schema the migrations create without variants
+const DefaultSchema = "default"
+
+// the types of the ids of a Schema
+const (
+	BigintIDs = "bigint"
+	SerialIDs = "serial"
+	UUIDIDs   = "uuid"
+)
+
+// Schema is a variant of the tables for the postgres dialects. Its zero value
+// is the design of the migrations: bigint user and comment ids, serial
+// article ids, foreign keys, an extra index on every primary key and NOT NULL
+// text columns.
+type Schema struct {
+	// NoForeignKeys leaves out the references of articles and comments
+	NoForeignKeys bool
+	// IDs is the type of all ids and references, bigint, serial or uuid
+	IDs string
+	// NoIDIndexes leaves out users_id_index, article_id_index and
+	// comments_id_index, the primary keys are indexed anyway
+	NoIDIndexes bool
+	// Varchar makes the text columns varchar(Varchar) instead of text
+	Varchar int
+	// Nullable leaves the NullableColumns without NOT NULL, so their
+	// generators can be nullable
+	Nullable bool
+}
+
+// NullableColumns are the columns the Nullable variant leaves nullable, by
+// the names of their generators
+var NullableColumns = []string{"users.description", "articles.text", "comments.text"}
+
+// schema is the variant of the running migrations, it is only set by Run
+var schema Schema
+
+// ParseSchema parses the variant name, the options differing from the
+// default joined by "+", e.g. "no-fk+uuid+varchar(255)". The options are
+// fk or no-fk, bigint, serial or uuid, id-index or no-id-index, text or
+// varchar(n) and not-null or nullable.
+func ParseSchema(name string) (Schema, error) {
+	var s Schema
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == DefaultSchema {
+		return s, nil
+	}
+
+	for _, option := range strings.Split(name, "+") {
+		option = strings.TrimSpace(option)
+		switch option {
+		case "fk":
+			s.NoForeignKeys = false
+		case "no-fk":
+			s.NoForeignKeys = true
+		case BigintIDs, SerialIDs, UUIDIDs:
+			s.IDs = option
+		case "id-index":
+			s.NoIDIndexes = false
+		case "no-id-index":
+			s.NoIDIndexes = true
+		case "text":
+			s.Varchar = 0
+		case "not-null":
+			s.Nullable = false
+		case "nullable":
+			s.Nullable = true
+		default:
+			var length int
+			if _, err := fmt.Sscanf(option, "varchar(%d)", &length); err != nil || length <= 0 || option != fmt.Sprintf("varchar(%d)", length) {
+				return Schema{}, fmt.Errorf("unknown schema option %q in %s, expected fk, no-fk, bigint, serial, uuid, id-index, no-id-index, text, varchar(n), not-null or nullable", option, name)
+			}
+			s.Varchar = length
+		}
+	}
+	return s, nil
+}
+
+// ParseSchemas parses a comma separated list of variant names like
+// "default,no-fk,uuid+no-id-index".
+func ParseSchemas(value string) ([]Schema, error) {
+	var schemas []Schema
+	for _, name := range strings.Split(value, ",") {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		s, err := ParseSchema(name)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+func (s Schema) IsDefault() bool {
+	return s == Schema{}
+}
+
+func (s Schema) String() string {
+	var options []string
+	if s.NoForeignKeys {
+		options = append(options, "no-fk")
+	}
+	if s.IDs != "" {
+		options = append(options, s.IDs)
+	}
+	if s.NoIDIndexes {
+		options = append(options, "no-id-index")
+	}
+	if s.Varchar > 0 {
+		options = append(options, fmt.Sprintf("varchar(%d)", s.Varchar))
+	}
+	if s.Nullable {
+		options = append(options, "nullable")
+	}
+	if len(options) == 0 {
+		return DefaultSchema
+	}
+	return strings.Join(options, "+")
+}
+
+// ID is the value of the id or reference at position, uuid ids keep the
+// order of the positions so the filters select the same rows
+func (s Schema) ID(position int) interface{} {
+	if s.IDs == UUIDIDs {
+		return fmt.Sprintf("00000000-0000-4000-8000-%012x", position)
+	}
+	return position
+}
+
+// idType is the type of the id of table
+func (s Schema) idType(table string) string {
+	if s.IDs == "" && table == "articles" {
+		return SerialIDs
+	}
+	if s.IDs == "" {
+		return BigintIDs
+	}
+	return s.IDs
+}
+
+// referenceType is the type of the columns referencing an id
+func (s Schema) referenceType() string {
+	switch s.IDs {
+	case SerialIDs:
+		return "integer"
+	case UUIDIDs:
+		return UUIDIDs
+	}
+	return BigintIDs
+}
+
+func (s Schema) references(table string) string {
+	if s.NoForeignKeys {
+		return ""
+	}
+	return " references " + table + " (id)"
+}
+
+func (s Schema) textType() string {
+	if s.Varchar > 0 {
+		return fmt.Sprintf("varchar(%d)", s.Varchar)
+	}
+	return "TEXT"
+}
+
+// NotNull reports if the column of the generator field, e.g. "articles.text",
+// is NOT NULL
+func (s Schema) NotNull(field string) bool {
+	if !s.Nullable {
+		return true
+	}
+	for _, column := range NullableColumns {
+		if column == field {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Schema) notNull(field string) string {
+	if s.NotNull(field) {
+		return " NOT NULL"
+	}
+	return ""
+}
+
+// idIndex is the redundant index of the primary key of table
+func (s Schema) idIndex(name, table string) string {
+	if s.NoIDIndexes {
+		return ""
+	}
+	return fmt.Sprintf(`
+
+	CREATE INDEX %s
+		  ON %s (id ASC);`, name, table)
+}
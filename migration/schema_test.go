@@ -0,0 +1,162 @@
+package migration
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseSchema(t *testing.T) {
+	for name, want := range map[string]Schema{
+		"":                                  {},
+		"Default":                           {},
+		"fk+bigint+id-index+text":           {IDs: BigintIDs},
+		"no-fk":                             {NoForeignKeys: true},
+		" uuid + no-id-index ":              {IDs: UUIDIDs, NoIDIndexes: true},
+		"no-fk+serial+varchar(255)":         {NoForeignKeys: true, IDs: SerialIDs, Varchar: 255},
+		"varchar(20)+text":                  {},
+		"no-fk+uuid+no-id-index+varchar(8)": {NoForeignKeys: true, IDs: UUIDIDs, NoIDIndexes: true, Varchar: 8},
+		"nullable+not-null":                 {},
+		"varchar(8)+nullable":               {Varchar: 8, Nullable: true},
+	} {
+		schema, err := ParseSchema(name)
+		if err != nil || schema != want {
+			t.Fatalf("ParseSchema(%q) = %+v, %v, want %+v", name, schema, err, want)
+		}
+		if again, err := ParseSchema(schema.String()); err != nil || again != schema {
+			t.Fatalf("%s does not parse to itself: %+v, %v", schema, again, err)
+		}
+	}
+
+	for _, name := range []string{"int", "varchar", "varchar(0)", "varchar(12", "no-fk+"} {
+		if _, err := ParseSchema(name); err == nil {
+			t.Fatalf("expected an error for %q", name)
+		}
+	}
+
+	schemas, err := ParseSchemas("default, no-fk,,uuid")
+	if err != nil || len(schemas) != 3 || schemas[2].IDs != UUIDIDs {
+		t.Fatalf("unexpected schemas %v, %v", schemas, err)
+	}
+}
+
+func TestUUIDsKeepTheOrder(t *testing.T) {
+	schema := Schema{IDs: UUIDIDs}
+	if id := schema.ID(255); id != "00000000-0000-4000-8000-0000000000ff" {
+		t.Fatalf("unexpected id %v", id)
+	}
+	if schema.ID(9).(string) >= schema.ID(10).(string) {
+		t.Fatal("the ids do not keep the order of the positions")
+	}
+	if id := (Schema{}).ID(7); id != 7 {
+		t.Fatalf("unexpected default id %v", id)
+	}
+}
+
+func TestSchemaVariantDDL(t *testing.T) {
+	tables := migrate(t, Schema{})
+	if !strings.Contains(tables["articles"], "serial") || !strings.Contains(tables["comments"], "references articles (id)") {
+		t.Fatalf("unexpected default articles %s, comments %s", tables["articles"], tables["comments"])
+	}
+	if tables["users_id_index"] == "" || tables["article_id_index"] == "" || tables["comments_id_index"] == "" {
+		t.Fatalf("the default schema lacks the id indexes: %v", tables)
+	}
+
+	tables = migrate(t, Schema{NoForeignKeys: true, IDs: UUIDIDs, NoIDIndexes: true, Varchar: 64})
+	for _, table := range []string{"users", "articles", "articles_simple", "comments", "comments_simple"} {
+		if strings.Contains(tables[table], "references") || strings.Contains(tables[table], "TEXT") || !strings.Contains(tables[table], "uuid") {
+			t.Fatalf("unexpected variant of %s: %s", table, tables[table])
+		}
+	}
+	if _, ok := tables["users_id_index"]; ok {
+		t.Fatal("the variant has the redundant users_id_index")
+	}
+	if tables["author_id_index"] == "" {
+		t.Fatal("the variant lacks the author_id_index")
+	}
+}
+
+func TestNullableVariantDDL(t *testing.T) {
+	tables := migrate(t, Schema{Nullable: true})
+	for table, nullable := range map[string]string{"users": "description", "articles": "text", "articles_simple": "text", "comments": "text", "comments_simple": "text"} {
+		for _, line := range strings.Split(tables[table], "\n") {
+			if strings.Contains(line, " "+nullable+" ") && strings.Contains(line, "NOT NULL") {
+				t.Fatalf("%s.%s is NOT NULL: %s", table, nullable, tables[table])
+			}
+		}
+		if !strings.Contains(tables[table], "NOT NULL") {
+			t.Fatalf("the other columns of %s are nullable: %s", table, tables[table])
+		}
+	}
+	if !(Schema{Nullable: true}).NotNull("articles.title") || (Schema{Nullable: true}).NotNull("articles.text") || !(Schema{}).NotNull("articles.text") {
+		t.Fatal("unexpected NotNull")
+	}
+}
+
+func TestConcurrentRunsMigrateTheirOwnSchema(t *testing.T) {
+	variants := []Schema{{}, {IDs: UUIDIDs, NoIDIndexes: true}}
+	tables := make([]map[string]string, len(variants))
+	var wg sync.WaitGroup
+	for i := range variants {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 5; n++ {
+				var err error
+				if tables[i], err = ddl(variants[i]); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if strings.Contains(tables[0]["users"], "uuid") || tables[0]["users_id_index"] == "" {
+		t.Fatalf("the default run got another schema: %v", tables[0])
+	}
+	if !strings.Contains(tables[1]["users"], "uuid") || tables[1]["users_id_index"] != "" {
+		t.Fatalf("the uuid run got another schema: %v", tables[1])
+	}
+}
+
+// migrate runs the migrations with schema on an in-memory SQLite database and
+// returns the DDL of its tables and indexes
+func migrate(t *testing.T, s Schema) map[string]string {
+	t.Helper()
+	tables, err := ddl(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tables
+}
+
+func ddl(s Schema) (map[string]string, error) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if err := Run(db, "up", Config{Dialect: SQLite, Schema: s}); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT name, sql FROM sqlite_master WHERE sql IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tables := map[string]string{}
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			return nil, err
+		}
+		tables[name] = ddl
+	}
+	return tables, rows.Err()
+}
@@ -0,0 +1,2 @@
+-- every run creates and drops a database of its own, named bench_<run id>
+GRANT ALL PRIVILEGES ON `bench\_%`.* TO 'test'@'%';
@@ -0,0 +1,313 @@
+// Package bench is the public API of the benchmark: create a Runner with a
+// backend, options and scenarios and call Run to get the Report.
+//
+//	backend := bench.Postgres(bench.DefaultPostgresDSN, true)
+//	report := bench.NewRunner(backend, bench.Options{Rows: 10000, Workers: 10}).Run(ctx)
+//	report.Log()
+//
+// Custom scenarios get the connection of the backend through its DB or Client
+// method and report every operation through Errors.Do, so the error policy,
+// retries and counters apply to them as well:
+//
+//	lookup := bench.Scenario{Name: "lookup", Run: func(ctx context.Context, errs *bench.Errors) int64 {
+//		return bench.RunWorkers(ctx, 1000, 10, func(position int) error {
+//			return errs.Do(ctx, func() error {
+//				_, err := backend.DB().ExecContext(ctx, `SELECT * FROM users WHERE id = $1`, position)
+//				return err
+//			})
+//		}).Done
+//	}}
+//	report := bench.NewRunner(backend, options, append(backend.Scenarios(), lookup)...).Run(ctx)
+package bench
+
+import (
+	"context"
+	_ "github.com/lib/pq"
+	core "postgres_performance_test/internal/bench"
+	"postgres_performance_test/internal/datagen"
+	"postgres_performance_test/internal/fake"
+	"postgres_performance_test/internal/fixture"
+	"postgres_performance_test/internal/mongodb"
+	"postgres_performance_test/internal/mysql"
+	"postgres_performance_test/internal/postgres"
+	"postgres_performance_test/internal/redis"
+	"postgres_performance_test/internal/sqlite"
+	"postgres_performance_test/migration"
+	"time"
+)
+
+type (
+	Backend         = core.Backend
+	Observer        = core.Observer
+	Scenario        = core.Scenario
+	Options         = core.Options
+	Runner          = core.Runner
+	Report          = core.Report
+	Comparison      = core.Comparison
+	Result          = core.Result
+	OpStats         = core.OpStats
+	Metrics         = core.Metrics
+	Errors          = core.Errors
+	ErrorClass      = core.ErrorClass
+	ErrorPolicy     = core.ErrorPolicy
+	RetryPolicy     = core.RetryPolicy
+	OnError         = core.OnError
+	TableSize       = core.TableSize
+	IndexSize       = core.IndexSize
+	PostgresBackend = postgres.Backend
+	MongoDBBackend  = mongodb.Backend
+	SQLiteBackend   = sqlite.Backend
+	MySQLBackend    = mysql.Backend
+	RedisBackend    = redis.Backend
+	Journal         = sqlite.Journal
+	Flavor          = postgres.Flavor
+	FakeBackend     = fake.Backend
+	FakeConfig      = fake.Config
+	FakeError       = fake.Error
+	Fault           = fake.Fault
+	Latency         = fake.Latency
+	Config          = core.Config
+	Profile         = core.Profile
+	Distribution    = core.Distribution
+	Schema          = migration.Schema
+	Importer        = core.Importer
+	Keeper          = core.Keeper
+	Cleanup         = core.Cleanup
+	FixtureSource   = fixture.Source
+	FixtureSources  = fixture.Sources
+	Generator       = datagen.Generator
+	FieldSpec       = datagen.Spec
+	Length          = datagen.Length
+)
+
+const (
+	Abort    = core.Abort
+	Continue = core.Continue
+	Retry    = core.Retry
+
+	ConstraintViolation  = core.ConstraintViolation
+	DuplicateKey         = core.DuplicateKey
+	Timeout              = core.Timeout
+	ConnectionReset      = core.ConnectionReset
+	SerializationFailure = core.SerializationFailure
+	OtherError           = core.OtherError
+
+	DefaultPostgresDSN = postgres.DefaultDSN
+	DefaultMongoDBURI  = mongodb.DefaultURI
+	DefaultSQLitePath  = sqlite.DefaultPath
+	SQLiteMemory       = sqlite.Memory
+	DefaultMySQLDSN    = mysql.DefaultDSN
+	DefaultRedisAddr   = redis.DefaultAddr
+	RedisInProcess     = redis.InProcess
+
+	WAL      = sqlite.WAL
+	Rollback = sqlite.Rollback
+
+	CustomProfileName = core.Custom
+	SkewedProfile     = core.Skewed
+
+	DefaultSchema = migration.DefaultSchema
+
+	CleanupAlways     = core.CleanupAlways
+	CleanupOnSuccess  = core.CleanupOnSuccess
+	CleanupNever      = core.CleanupNever
+	RunPrefix         = core.RunPrefix
+	DefaultCleanupAge = core.DefaultCleanupAge
+
+	Vanilla   = postgres.Vanilla
+	Cockroach = postgres.Cockroach
+	Yugabyte  = postgres.Yugabyte
+)
+
+// NewRunner creates a runner of scenarios, the built-in scenarios of the
+// backend are run if none are given.
+func NewRunner(backend Backend, options Options, scenarios ...Scenario) *Runner {
+	return core.NewRunner(backend, options, scenarios...)
+}
+
+// Postgres is the PostgreSQL backend, the tables are created by the
+// migrations in a schema of every run if runMigrations is set, the existing
+// tables of the DSN are used otherwise.
+func Postgres(dsn string, runMigrations bool) *PostgresBackend {
+	return postgres.New(dsn, runMigrations)
+}
+
+func ParseFlavor(value string) (Flavor, error) {
+	return postgres.ParseFlavor(value)
+}
+
+func MongoDB(uri string) *MongoDBBackend {
+	return mongodb.New(uri)
+}
+
+// SQLite is the SQLite backend for the database file at path or SQLiteMemory,
+// journal is WAL or Rollback.
+func SQLite(path string, journal Journal) *SQLiteBackend {
+	return sqlite.New(path, journal)
+}
+
+// MySQL is the MySQL or MariaDB backend, the DSN needs multiStatements for the
+// migrations and the server local_infile for the LOAD DATA bulk load.
+func MySQL(dsn string) *MySQLBackend {
+	return mysql.New(dsn)
+}
+
+// Redis is the Redis backend for the server at addr, RedisInProcess starts an
+// in-process stand-in instead.
+func Redis(addr string) *RedisBackend {
+	return redis.New(addr)
+}
+
+// Fake is a backend without a database, it keeps the rows in memory and adds
+// the latency and faults of config to every operation, for testing the harness
+// and custom scenarios.
+func Fake(config FakeConfig) *FakeBackend {
+	return fake.New(config)
+}
+
+func ConstantLatency(d time.Duration) Latency {
+	return fake.Constant(d)
+}
+
+func UniformLatency(min, max time.Duration) Latency {
+	return fake.Uniform(min, max)
+}
+
+func NormalLatency(mean, stddev time.Duration) Latency {
+	return fake.Normal(mean, stddev)
+}
+
+// LongTailLatency is log-normal with the given median, sigma 1 puts p99 at
+// about ten times the median.
+func LongTailLatency(median time.Duration, sigma float64) Latency {
+	return fake.LongTail(median, sigma)
+}
+
+// LoadConfig reads the benchmark config file at path.
+func LoadConfig(path string) (Config, error) {
+	return core.LoadConfig(path)
+}
+
+// DataGenerator creates the generator of the inserted values, specs override
+// the default generators of the fields like "articles.text".
+func DataGenerator(seed int64, specs map[string]FieldSpec) (*Generator, error) {
+	return datagen.New(seed, specs)
+}
+
+// Profiles are the built-in dataset profiles tiny, small, medium and large
+// with evenly spread references and their skewed variants, e.g. large-skewed
+var Profiles = core.Profiles
+
+// ProfileNames are the names of the profiles in the order of their size
+var ProfileNames = core.ProfileNames
+
+// CustomProfile has rows users, articles and comments.
+func CustomProfile(rows int) Profile {
+	return core.CustomProfile(rows)
+}
+
+// ParseProfile looks up the profile name in defined, the profiles of the
+// config file, and then in the built-in ones.
+func ParseProfile(name string, defined map[string]Profile, rows int) (Profile, error) {
+	return core.ParseProfile(name, defined, rows)
+}
+
+// ParseSchemas parses a comma separated list of schema variants, e.g.
+// "default,no-fk,uuid+no-id-index,varchar(255)".
+func ParseSchemas(value string) ([]Schema, error) {
+	return migration.ParseSchemas(value)
+}
+
+// Generate writes the rows of the profile to users, articles and comments
+// files of format csv, jsonl or copy in dir, the rows the benchmark loads with
+// the seed of data.
+func Generate(dir, format string, profile Profile, data *Generator) error {
+	return core.Generate(dir, format, profile, data)
+}
+
+// FindFixtures looks for the users, articles and comments CSV or JSONL files
+// in dir.
+func FindFixtures(dir string) (FixtureSources, error) {
+	return fixture.Find(dir)
+}
+
+// PayloadSizes are the text sizes of a full payload sweep, the sweep only
+// runs with the sizes of Options.PayloadSizes
+var PayloadSizes = core.PayloadSizes
+
+// ParseSizes parses a comma separated list of sizes like "100,2KB,1MB".
+func ParseSizes(value string) ([]int, error) {
+	return core.ParseSizes(value)
+}
+
+func ParseJournal(value string) (Journal, error) {
+	return sqlite.ParseJournal(value)
+}
+
+// Unsupported marks scenario as one the backend can not run for reason, it is
+// reported as skipped.
+func Unsupported(scenario Scenario, reason string) Scenario {
+	return core.Unsupported(scenario, reason)
+}
+
+// Check wraps a step that verifies or inspects the data into a Scenario.
+func Check(name string, check func(ctx context.Context, errs *Errors)) Scenario {
+	return core.Check(name, check)
+}
+
+// RunWorkers calls op for every position of [0, total), split between workers
+// goroutines, until ctx is cancelled.
+func RunWorkers(ctx context.Context, total, workers int, op func(position int) error) Metrics {
+	return core.RunWorkers(ctx, total, workers, op)
+}
+
+// RunLoop makes every one of workers goroutines call op perWorker times, until
+// ctx is cancelled. op gets the position of the call, worker w makes the calls
+// [w*perWorker, (w+1)*perWorker).
+func RunLoop(ctx context.Context, workers, perWorker int, op func(position int) error) Metrics {
+	return core.RunLoop(ctx, workers, perWorker, op)
+}
+
+func ParseOnError(value string) (OnError, error) {
+	return core.ParseOnError(value)
+}
+
+func ParseCleanup(value string) (Cleanup, error) {
+	return core.ParseCleanup(value)
+}
+
+// NewRunID is the id of a run started now, the schema or database of the run
+// is RunPrefix followed by it.
+func NewRunID() string {
+	return core.NewRunID()
+}
+
+// CleanupCutoff is the start time before which the cleanup removes the runs,
+// olderThan must be positive so the running benchmarks keep their data.
+func CleanupCutoff(olderThan time.Duration) (time.Time, error) {
+	return core.CleanupCutoff(olderThan)
+}
+
+// CleanupPostgres drops the schemas of the runs started before cutoff and
+// returns their names, with dryRun it only lists them.
+func CleanupPostgres(ctx context.Context, dsn string, cutoff time.Time, dryRun bool) ([]string, error) {
+	return postgres.Cleanup(ctx, dsn, cutoff, dryRun)
+}
+
+// CleanupMongoDB drops the databases of the runs started before cutoff and
+// returns their names, with dryRun it only lists them.
+func CleanupMongoDB(ctx context.Context, uri string, cutoff time.Time, dryRun bool) ([]string, error) {
+	return mongodb.Cleanup(ctx, uri, cutoff, dryRun)
+}
+
+// CleanupMySQL drops the databases of the runs started before cutoff and
+// returns their names, with dryRun it only lists them.
+func CleanupMySQL(ctx context.Context, dsn string, cutoff time.Time, dryRun bool) ([]string, error) {
+	return mysql.Cleanup(ctx, dsn, cutoff, dryRun)
+}
+
+// CleanupRedis removes the keys of the runs started before cutoff and returns
+// the prefixes of the runs, with dryRun it only lists them.
+func CleanupRedis(ctx context.Context, addr string, cutoff time.Time, dryRun bool) ([]string, error) {
+	return redis.Cleanup(ctx, addr, cutoff, dryRun)
+}
@@ -0,0 +1,35 @@
+// Package benchtest runs the scenarios of the bench package as Go
+// benchmarks, to be compared with benchstat:
+//
+//	func BenchmarkPostgresSelectUsersById(b *testing.B) {
+//		benchtest.BenchmarkScenario(b, bench.Postgres(bench.DefaultPostgresDSN, true),
+//			bench.Options{Rows: 10000, Workers: 16}, "select users by id")
+//	}
+//
+// It is apart from the bench package, so the binaries importing that do not
+// link testing.
+package benchtest
+
+import (
+	"postgres_performance_test/internal/bench/benchtest"
+	"postgres_performance_test/pkg/bench"
+	"testing"
+)
+
+// BenchmarkScenario runs the scenario called name as a Go benchmark. The
+// backend is set up and the scenarios before name are run untimed to load the
+// data it needs, then the Op of the scenario is run b.N times by BenchmarkOp.
+// Scenarios without an Op are skipped.
+func BenchmarkScenario(b *testing.B, backend bench.Backend, options bench.Options, name string) {
+	b.Helper()
+	benchtest.BenchmarkScenario(b, backend, options, name)
+}
+
+// BenchmarkOp runs op b.N times through b.RunParallel with at least workers
+// goroutines, every call gets a distinct position starting at 0. Besides
+// ns/op it reports rows/s, the p50, p95 and p99 latencies and the failed
+// operations, if any.
+func BenchmarkOp(b *testing.B, workers int, op func(position int) error) {
+	b.Helper()
+	benchtest.BenchmarkOp(b, workers, op)
+}